@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// cacheStatusKey is the context key SetCacheStatus stores the cache status under.
+const cacheStatusKey = "_gin-contrib/logger_cache_status_"
+
+// Cache statuses for SetCacheStatus.
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheStale = "stale"
+)
+
+// SetCacheStatus records how a cache served the request, usually CacheHit,
+// CacheMiss or CacheStale, as the "cache_status" field of the access line, so
+// caching middleware and handlers report cacheability the same way. The last
+// call wins.
+func SetCacheStatus(c *gin.Context, status string) {
+	c.Set(cacheStatusKey, status)
+}
+
+// coalescedKey is the context key MarkCoalesced stores the leader request ID under.
+const coalescedKey = "_gin-contrib/logger_coalesced_"
+
+// MarkCoalesced records that the request was served from a result shared with
+// other requests, e.g. through singleflight, computed by the request identified
+// by leader. The access line then carries coalesced=true and coalesced_with,
+// making cache-stampede protections observable. Leaders should not be marked.
+func MarkCoalesced(c *gin.Context, leader string) {
+	c.Set(coalescedKey, leader)
+}
+
+// annotationFields adds the annotations recorded on the context by the handler.
+func annotationFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	if status := c.GetString(cacheStatusKey); status != "" {
+		evt = evt.Str("cache_status", status)
+	}
+	if v, ok := c.Get(coalescedKey); ok {
+		evt = evt.Bool("coalesced", true)
+		if leader, _ := v.(string); leader != "" {
+			evt = evt.Str("coalesced_with", leader)
+		}
+	}
+	return evt
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerMarkCoalesced(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer)))
+	r.GET("/follower", func(c *gin.Context) {
+		MarkCoalesced(c, "req-1")
+	})
+	r.GET("/leader", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/follower")
+	assert.Contains(t, buffer.String(), "coalesced=true coalesced_with=req-1")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/leader")
+	assert.NotContains(t, buffer.String(), "coalesced")
+}
+
+func TestLoggerSetCacheStatus(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer)))
+	r.Use(func(c *gin.Context) {
+		SetCacheStatus(c, CacheMiss)
+	})
+	r.GET("/cached", func(c *gin.Context) {
+		SetCacheStatus(c, CacheStale)
+	})
+	r.GET("/fresh", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/cached")
+	assert.Contains(t, buffer.String(), "cache_status=stale")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/fresh")
+	assert.Contains(t, buffer.String(), "cache_status=miss")
+}
@@ -0,0 +1,246 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// BackpressurePolicy decides what an AsyncWriter does when its queue is full.
+type BackpressurePolicy int
+
+func (p BackpressurePolicy) String() string {
+	switch p {
+	case BackpressureDropNewest:
+		return "drop_newest"
+	case BackpressureDropOldest:
+		return "drop_oldest"
+	case BackpressureSample:
+		return "sample"
+	default:
+		return "block"
+	}
+}
+
+const (
+	// BackpressureBlock makes the request wait until there is room in the
+	// queue: no event is lost, at the cost of latency.
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDropNewest discards the event being written.
+	BackpressureDropNewest
+	// BackpressureDropOldest discards the oldest queued event to make room.
+	BackpressureDropOldest
+	// BackpressureSample keeps one in ten events below warn level once the
+	// queue is half full, and discards the event being written when it is full.
+	BackpressureSample
+)
+
+// ErrWriterClosed is returned by AsyncWriter.Flush and ObjectShipper.Write once
+// the writer is closed.
+var ErrWriterClosed = errors.New("logger: writer closed")
+
+// sampleUnderPressure is the 1 in N rate applied by BackpressureSample.
+const sampleUnderPressure = 10
+
+// AsyncStats counts what an AsyncWriter did with the events written to it.
+type AsyncStats struct {
+	// Enqueued is the number of events accepted into the queue.
+	Enqueued int64
+	// Dropped is the number of events discarded because the queue was full or
+	// the writer was closed.
+	Dropped int64
+	// Sampled is the number of events discarded by BackpressureSample before the queue was full.
+	Sampled int64
+	// Blocked is the number of writes that had to wait for room with BackpressureBlock.
+	Blocked int64
+}
+
+type asyncEvent struct {
+	p    []byte
+	done chan struct{}
+}
+
+// AsyncWriter moves writing, and formatting when it wraps a ConsoleWriter, off
+// the request path: events are queued and written by a background goroutine.
+// When the queue is full the BackpressurePolicy applies.
+type AsyncWriter struct {
+	w      io.Writer
+	policy BackpressurePolicy
+	queue  chan asyncEvent
+	health *pipelineHealth
+	wg     sync.WaitGroup
+
+	// mu orders writes and Flush, which hold it for reading, with Close, so
+	// nothing is sent on the closed queue.
+	mu     sync.RWMutex
+	closed bool
+
+	seq      atomic.Int64
+	enqueued atomic.Int64
+	dropped  atomic.Int64
+	skipped  atomic.Int64
+	blocked  atomic.Int64
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to w through a queue of
+// size events. Close it to write the queued events and stop its goroutine.
+func NewAsyncWriter(w io.Writer, size int, policy BackpressurePolicy) *AsyncWriter {
+	return newAsyncWriter(w, size, policy, health)
+}
+
+// newAsyncWriter is NewAsyncWriter reporting to the health h.
+func newAsyncWriter(w io.Writer, size int, policy BackpressurePolicy, h *pipelineHealth) *AsyncWriter {
+	if size < 1 {
+		size = 1
+	}
+	a := &AsyncWriter{
+		w:      w,
+		policy: policy,
+		queue:  make(chan asyncEvent, size),
+		health: h,
+	}
+	a.wg.Add(1)
+	go a.run()
+	return a
+}
+
+func (a *AsyncWriter) run() {
+	defer a.wg.Done()
+	for evt := range a.queue {
+		if evt.done != nil {
+			close(evt.done)
+			continue
+		}
+		_, err := a.w.Write(evt.p)
+		a.health.recordWrite(err, 1)
+	}
+}
+
+// Write queues p as an event without level.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	return a.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel queues p according to the backpressure policy. The level is used
+// by BackpressureSample to spare warnings and errors. Events written after
+// Close are dropped.
+func (a *AsyncWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		a.dropped.Add(1)
+		a.health.recordDrop()
+		return len(p), nil
+	}
+
+	if a.policy == BackpressureSample && level < zerolog.WarnLevel && level != zerolog.NoLevel &&
+		len(a.queue) >= cap(a.queue)/2 && a.seq.Add(1)%sampleUnderPressure != 0 {
+		a.skipped.Add(1)
+		a.health.recordDrop()
+		return len(p), nil
+	}
+
+	evt := asyncEvent{p: append([]byte(nil), p...)}
+	select {
+	case a.queue <- evt:
+		a.enqueued.Add(1)
+		return len(p), nil
+	default:
+	}
+
+	switch a.policy {
+	case BackpressureBlock:
+		a.blocked.Add(1)
+		a.queue <- evt
+		a.enqueued.Add(1)
+		return len(p), nil
+	case BackpressureDropOldest:
+		for {
+			select {
+			case old := <-a.queue:
+				if old.done != nil {
+					close(old.done)
+				} else {
+					a.dropped.Add(1)
+					a.health.recordDrop()
+				}
+			default:
+			}
+			select {
+			case a.queue <- evt:
+				a.enqueued.Add(1)
+				return len(p), nil
+			default:
+			}
+		}
+	default:
+		a.dropped.Add(1)
+		a.health.recordDrop()
+		return len(p), nil
+	}
+}
+
+// Len returns the number of queued events.
+func (a *AsyncWriter) Len() int {
+	return len(a.queue)
+}
+
+// Stats returns the counters of the writer.
+func (a *AsyncWriter) Stats() AsyncStats {
+	return AsyncStats{
+		Enqueued: a.enqueued.Load(),
+		Dropped:  a.dropped.Load(),
+		Sampled:  a.skipped.Load(),
+		Blocked:  a.blocked.Load(),
+	}
+}
+
+// Flush waits until the events queued before the call have been written. It
+// returns ErrWriterClosed once the writer is closed.
+func (a *AsyncWriter) Flush() error {
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+		return ErrWriterClosed
+	}
+	done := make(chan struct{})
+	a.queue <- asyncEvent{done: done}
+	a.mu.RUnlock()
+	<-done
+	return nil
+}
+
+// Close writes the queued events and stops the background goroutine. Events
+// written afterwards are dropped.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+	a.wg.Wait()
+	return nil
+}
+
+// levelSplitWriter writes events at or above a level to a dedicated writer and
+// all other events to the regular output.
+type levelSplitWriter struct {
+	min  zerolog.Level
+	high io.Writer
+	low  zerolog.LevelWriter
+}
+
+func (w levelSplitWriter) Write(p []byte) (int, error) {
+	return w.low.Write(p)
+}
+
+func (w levelSplitWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level >= w.min && level != zerolog.NoLevel {
+		return w.high.Write(p)
+	}
+	return w.low.WriteLevel(level, p)
+}
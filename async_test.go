@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedWriter blocks every write until the gate is opened.
+type gatedWriter struct {
+	countingWriter
+	gate chan struct{}
+}
+
+func (g *gatedWriter) Write(p []byte) (int, error) {
+	<-g.gate
+	return g.countingWriter.Write(p)
+}
+
+// newStalledAsyncWriter returns an AsyncWriter whose goroutine is stuck on a
+// first event, so the queue fills deterministically.
+func newStalledAsyncWriter(size int, policy BackpressurePolicy) (*AsyncWriter, *gatedWriter) {
+	out := &gatedWriter{gate: make(chan struct{})}
+	a := NewAsyncWriter(out, size, policy)
+	_, _ = a.Write([]byte("stalled\n"))
+	for a.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	return a, out
+}
+
+func TestAsyncWriterDropNewest(t *testing.T) {
+	a, out := newStalledAsyncWriter(2, BackpressureDropNewest)
+	for i := 0; i < 4; i++ {
+		_, _ = a.Write([]byte(fmt.Sprintf("%d\n", i)))
+	}
+	close(out.gate)
+	assert.NoError(t, a.Close())
+	assert.Equal(t, []string{"stalled\n", "0\n", "1\n"}, out.writes)
+	assert.Equal(t, AsyncStats{Enqueued: 3, Dropped: 2}, a.Stats())
+}
+
+func TestAsyncWriterDropOldest(t *testing.T) {
+	a, out := newStalledAsyncWriter(2, BackpressureDropOldest)
+	for i := 0; i < 4; i++ {
+		_, _ = a.Write([]byte(fmt.Sprintf("%d\n", i)))
+	}
+	close(out.gate)
+	assert.NoError(t, a.Close())
+	assert.Equal(t, []string{"stalled\n", "2\n", "3\n"}, out.writes)
+	assert.Equal(t, int64(2), a.Stats().Dropped)
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	a, out := newStalledAsyncWriter(1, BackpressureBlock)
+	_, _ = a.Write([]byte("0\n"))
+	written := make(chan struct{})
+	go func() {
+		_, _ = a.Write([]byte("1\n"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("write should block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(out.gate)
+	<-written
+	assert.NoError(t, a.Close())
+	assert.Equal(t, []string{"stalled\n", "0\n", "1\n"}, out.writes)
+	assert.Equal(t, int64(1), a.Stats().Blocked)
+}
+
+func TestAsyncWriterSample(t *testing.T) {
+	a, out := newStalledAsyncWriter(20, BackpressureSample)
+	for i := 0; i < 10; i++ {
+		_, _ = a.WriteLevel(zerolog.InfoLevel, []byte("info\n"))
+	}
+	for i := 0; i < 20; i++ {
+		_, _ = a.WriteLevel(zerolog.InfoLevel, []byte("sampled\n"))
+	}
+	_, _ = a.WriteLevel(zerolog.ErrorLevel, []byte("error\n"))
+	close(out.gate)
+	assert.NoError(t, a.Close())
+
+	all := strings.Join(out.writes, "")
+	assert.Equal(t, 10, strings.Count(all, "info"))
+	assert.Equal(t, 2, strings.Count(all, "sampled"))
+	assert.Equal(t, 1, strings.Count(all, "error"))
+	assert.Equal(t, int64(18), a.Stats().Sampled)
+}
+
+func TestLoggerAsync(t *testing.T) {
+	out := new(countingWriter)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(out), WithAsync(16), WithBackpressurePolicy(BackpressureDropOldest)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.NoError(t, Flush())
+	assert.Equal(t, 1, out.count())
+	assert.Contains(t, out.writes[0], "/example")
+	assert.Contains(t, Health().Backpressure, "drop_oldest")
+}
+
+func TestLoggerGuaranteedErrorDelivery(t *testing.T) {
+	out := new(countingWriter)
+	errOut := new(countingWriter)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(out), WithBatching(100, 0, time.Hour), WithGuaranteedErrorDelivery(errOut)))
+	r.GET("/ok", func(c *gin.Context) {})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	performRequest(r, "GET", "/ok")
+	performRequest(r, "GET", "/fail")
+	assert.Equal(t, 0, out.count())
+	assert.Equal(t, 1, errOut.count())
+	assert.Contains(t, errOut.writes[0], "/fail")
+
+	assert.NoError(t, Flush())
+	assert.Equal(t, 1, out.count())
+	assert.Contains(t, out.writes[0], "/ok")
+	assert.NotContains(t, out.writes[0], "/fail")
+}
+
+func TestAsyncWriterFlushAfterClose(t *testing.T) {
+	a := NewAsyncWriter(new(countingWriter), 1, BackpressureBlock)
+	assert.NoError(t, a.Close())
+	assert.NoError(t, a.Close())
+	assert.ErrorIs(t, a.Flush(), ErrWriterClosed)
+}
@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// auditHashField is the JSON field added to every record by AuditWriter.
+const auditHashField = "prev_hash"
+
+// ErrChainBroken is returned by VerifyChain when a record's prev_hash does not
+// match the SHA-256 of the record written before it.
+var ErrChainBroken = errors.New("logger: audit chain broken")
+
+// genesisHash is the prev_hash of the first record in a chain.
+var genesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// AuditWriter is an io.Writer that chains JSON log records together for
+// tamper detection. Every record gets a "prev_hash" field holding the hex encoded
+// SHA-256 of the previous record as written, so modifying, removing or reordering
+// any line breaks the chain. Records must be JSON objects, one per Write call, which
+// is what zerolog produces when it is not wrapped in a ConsoleWriter.
+type AuditWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	prev string
+}
+
+// NewAuditWriter returns an AuditWriter writing chained records to w, starting
+// a new chain. Use ResumeAuditWriter to append to an existing one.
+func NewAuditWriter(w io.Writer) *AuditWriter {
+	return &AuditWriter{w: w, prev: genesisHash}
+}
+
+// ResumeAuditWriter returns an AuditWriter appending chained records to w after
+// the records read from existing, typically the file w appends to, so the chain
+// survives restarts:
+//
+//	f, err := os.OpenFile("audit.log", os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+//	...
+//	audit, err := logger.ResumeAuditWriter(f, f)
+//
+// The existing records are verified as by VerifyChain, and an error is returned
+// when their chain is broken, rather than extending it. An empty existing starts
+// a new chain.
+func ResumeAuditWriter(w io.Writer, existing io.Reader) (*AuditWriter, error) {
+	prev, err := chainTip(existing)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditWriter{w: w, prev: prev}, nil
+}
+
+// Write adds the prev_hash field to the JSON record p and writes it to the underlying writer.
+func (a *AuditWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimRight(p, "\n")
+	if len(line) < 2 || line[0] != '{' || line[len(line)-1] != '}' {
+		return 0, errors.New("logger: audit writer expects one JSON object per write")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record := appendHashField(make([]byte, 0, len(line)+len(auditHashField)+72), line, a.prev)
+	sum := sha256.Sum256(record)
+	if _, err := a.w.Write(append(record, '\n')); err != nil {
+		return 0, err
+	}
+	a.prev = hex.EncodeToString(sum[:])
+	return len(p), nil
+}
+
+// appendHashField appends the JSON object line to dst with the prev_hash field
+// inserted before its closing brace.
+func appendHashField(dst, line []byte, hash string) []byte {
+	dst = append(dst, line[:len(line)-1]...)
+	if len(bytes.TrimSpace(line[1:len(line)-1])) > 0 {
+		dst = append(dst, ',')
+	}
+	dst = append(dst, '"')
+	dst = append(dst, auditHashField...)
+	dst = append(dst, `":"`...)
+	dst = append(dst, hash...)
+	return append(dst, '"', '}')
+}
+
+// VerifyChain reads newline-delimited records produced by an AuditWriter from r
+// and checks that every record's prev_hash matches the SHA-256 of the record before it.
+// It returns an error wrapping ErrChainBroken that names the first offending line.
+func VerifyChain(r io.Reader) error {
+	_, err := chainTip(r)
+	return err
+}
+
+// chainTip verifies the chain of the records read from r and returns the hash
+// of the last one, the prev_hash of the record following them.
+func chainTip(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	prev := genesisHash
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Bytes()
+		var record struct {
+			PrevHash *string `json:"prev_hash"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return "", fmt.Errorf("line %d: %w: %w", n, ErrChainBroken, err)
+		}
+		if record.PrevHash == nil || *record.PrevHash != prev {
+			return "", fmt.Errorf("line %d: %w", n, ErrChainBroken)
+		}
+		sum := sha256.Sum256(line)
+		prev = hex.EncodeToString(sum[:])
+	}
+	return prev, scanner.Err()
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditWriter(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	audit := NewAuditWriter(buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
+			return l.Output(audit).With().Logger()
+		}),
+	))
+	r.GET("/example", func(c *gin.Context) {})
+
+	for i := 0; i < 3; i++ {
+		performRequest(r, "GET", "/example")
+	}
+	assert.Equal(t, 3, strings.Count(buffer.String(), `"prev_hash":"`))
+	assert.Contains(t, buffer.String(), `"prev_hash":"`+genesisHash+`"`)
+	assert.NoError(t, VerifyChain(strings.NewReader(buffer.String())))
+
+	lines := strings.SplitAfter(buffer.String(), "\n")
+
+	tampered := strings.Replace(buffer.String(), `"status":200`, `"status":201`, 1)
+	err := VerifyChain(strings.NewReader(tampered))
+	assert.True(t, errors.Is(err, ErrChainBroken))
+	assert.Contains(t, err.Error(), "line 2")
+
+	removed := lines[0] + lines[2]
+	err = VerifyChain(strings.NewReader(removed))
+	assert.True(t, errors.Is(err, ErrChainBroken))
+}
+
+func TestAuditWriterRejectsNonJSON(t *testing.T) {
+	audit := NewAuditWriter(new(bytes.Buffer))
+	_, err := audit.Write([]byte("plain text\n"))
+	assert.Error(t, err)
+
+	n, err := audit.Write([]byte("{}\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestResumeAuditWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	open := func() *os.File {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	// Each run of the process appends to the chain of the previous one.
+	for run := 0; run < 3; run++ {
+		f := open()
+		audit, err := ResumeAuditWriter(f, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			_, err = audit.Write([]byte(`{"run":` + strconv.Itoa(run) + `}` + "\n"))
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, f.Close())
+	}
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, strings.Count(string(b), `"prev_hash":"`))
+	assert.Equal(t, 1, strings.Count(string(b), `"prev_hash":"`+genesisHash+`"`))
+	assert.NoError(t, VerifyChain(bytes.NewReader(b)))
+
+	// A broken chain is not extended.
+	assert.NoError(t, os.WriteFile(path, bytes.Replace(b, []byte(`"run":1`), []byte(`"run":9`), 1), 0o600))
+	f := open()
+	defer f.Close()
+	_, err = ResumeAuditWriter(f, f)
+	assert.ErrorIs(t, err, ErrChainBroken)
+}
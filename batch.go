@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// BatchWriter coalesces events into fewer writes to the underlying writer,
+// which saves syscalls for files and requests for network writers. A batch is
+// written when it reaches maxEvents events or maxBytes bytes, or flushInterval
+// after its first event, whichever comes first.
+//
+// Events are buffered in memory: call Flush, or Close, during shutdown so the
+// last batch is not lost. A batch that fails to be written is dropped: the
+// failure is reported by Health, and returned by the Flush or Close call
+// writing the batch, but not by Write, which only buffers the event.
+type BatchWriter struct {
+	w         io.Writer
+	maxEvents int
+	maxBytes  int
+	interval  time.Duration
+	health    *pipelineHealth
+
+	mu     sync.Mutex
+	buf    []byte
+	events int
+	timer  *time.Timer
+}
+
+// NewBatchWriter returns a BatchWriter writing batches to w. Zero or negative
+// limits disable the corresponding trigger.
+func NewBatchWriter(w io.Writer, maxEvents, maxBytes int, flushInterval time.Duration) *BatchWriter {
+	return &BatchWriter{
+		w:         w,
+		maxEvents: maxEvents,
+		maxBytes:  maxBytes,
+		interval:  flushInterval,
+		health:    health,
+	}
+}
+
+// Write adds the event p to the current batch, writing the batch when it is
+// full. It never fails: errors writing a batch are reported by Health.
+func (b *BatchWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.maxBytes > 0 && b.events > 0 && len(b.buf)+len(p) > b.maxBytes {
+		_ = b.flushLocked()
+	}
+
+	b.buf = append(b.buf, p...)
+	b.events++
+
+	switch {
+	case (b.maxEvents > 0 && b.events >= b.maxEvents) || (b.maxBytes > 0 && len(b.buf) >= b.maxBytes):
+		_ = b.flushLocked()
+	case b.timer == nil && b.interval > 0:
+		b.timer = time.AfterFunc(b.interval, func() { _ = b.Flush() })
+	}
+	return len(p), nil
+}
+
+// Flush writes the buffered events to the underlying writer.
+func (b *BatchWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushLocked()
+}
+
+// Close flushes the buffered events. It does not close the underlying writer.
+func (b *BatchWriter) Close() error {
+	return b.Flush()
+}
+
+// reportTo makes the batch writer, and its underlying writer when it reports
+// to a health too, report to the health h of the pipeline writing to it.
+func (b *BatchWriter) reportTo(h *pipelineHealth) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.health = h
+	if r, ok := b.w.(healthReporter); ok {
+		r.reportTo(h)
+	}
+}
+
+// Len returns the number of buffered events.
+func (b *BatchWriter) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.events
+}
+
+func (b *BatchWriter) flushLocked() error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.events == 0 {
+		return nil
+	}
+
+	_, err := b.w.Write(b.buf)
+	b.health.recordWrite(err, b.events)
+	b.buf = b.buf[:0]
+	b.events = 0
+	return err
+}
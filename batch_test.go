@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingWriter records every write it receives.
+type countingWriter struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *countingWriter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.writes)
+}
+
+func TestBatchWriterLimits(t *testing.T) {
+	out := new(countingWriter)
+	b := NewBatchWriter(out, 3, 10, 0)
+
+	_, _ = b.Write([]byte("a\n"))
+	_, _ = b.Write([]byte("b\n"))
+	assert.Equal(t, 0, out.count())
+	assert.Equal(t, 2, b.Len())
+
+	_, _ = b.Write([]byte("c\n"))
+	assert.Equal(t, []string{"a\nb\nc\n"}, out.writes)
+
+	// Exceeding maxBytes writes the pending batch before buffering the event.
+	_, _ = b.Write([]byte("1234\n"))
+	_, _ = b.Write([]byte("123456\n"))
+	assert.Equal(t, []string{"a\nb\nc\n", "1234\n"}, out.writes)
+
+	assert.NoError(t, b.Close())
+	assert.Equal(t, "123456\n", out.writes[2])
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestBatchWriterInterval(t *testing.T) {
+	out := new(countingWriter)
+	b := NewBatchWriter(out, 0, 0, 10*time.Millisecond)
+
+	_, _ = b.Write([]byte("a\n"))
+	assert.Equal(t, 0, out.count())
+	assert.Eventually(t, func() bool { return out.count() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestLoggerBatching(t *testing.T) {
+	out := new(countingWriter)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(out), WithBatching(100, 1<<20, time.Hour)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	for i := 0; i < 5; i++ {
+		performRequest(r, "GET", "/example")
+	}
+	assert.Equal(t, 0, out.count())
+	assert.GreaterOrEqual(t, Health().QueueDepth, 5)
+
+	assert.NoError(t, Flush())
+	assert.Equal(t, 1, out.count())
+	assert.Equal(t, 5, strings.Count(out.writes[0], "path=/example"))
+}
+
+func TestBatchWriterFlushError(t *testing.T) {
+	dropped := Health().DroppedEvents
+	b := NewBatchWriter(failingWriter{}, 2, 0, 0)
+	_, err := b.Write([]byte("a\n"))
+	assert.NoError(t, err)
+	// The failed batch is dropped and reported by Health, not by Write.
+	_, err = b.Write([]byte("b\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, dropped+2, Health().DroppedEvents)
+	assert.Equal(t, 0, b.Len())
+
+	_, _ = b.Write([]byte("c\n"))
+	assert.Error(t, b.Flush())
+}
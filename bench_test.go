@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// benchCase is a middleware configuration measured by the benchmarks and the
+// allocation budget.
+type benchCase struct {
+	name string
+	opts []Option
+	// body is sent as a JSON request body when set.
+	body string
+	// allocs is the maximum number of allocations per request.
+	allocs float64
+}
+
+var benchCases = []benchCase{
+	{name: "skipped", opts: []Option{WithSkipPath([]string{"/users/42"})}, allocs: 10},
+	{name: "json", opts: []Option{WithAutoFormat()}, allocs: 16},
+	{name: "console", allocs: 16},
+	{name: "gin", opts: []Option{WithGinFormat()}, allocs: 37},
+	{name: "static-fields", opts: []Option{WithAutoFormat(), WithStaticFields(ServiceFields("api", "1.2.3"))}, allocs: 16},
+	{name: "json-no-context-logger", opts: []Option{WithAutoFormat(), WithContextLoggerDisabled(true)}, allocs: 11},
+	{name: "enriched", opts: []Option{
+		WithAutoFormat(),
+		WithCorrelationID(),
+		WithNetworkClassifier(map[string]string{"10.0.0.0/8": "internal"}),
+		WithRouteParams(true),
+		WithSplitQuery(true),
+		WithTimingSplit(),
+	}, allocs: 36},
+	{name: "body", opts: []Option{WithAutoFormat(), WithBodyFields("user.id")}, body: `{"user":{"id":7}}`, allocs: 52},
+}
+
+// rewindBody is a request body that can be read again after a rewind, so
+// requests are reused without allocating.
+type rewindBody struct {
+	*bytes.Reader
+}
+
+func (rewindBody) Close() error { return nil }
+
+// discardResponse is a http.ResponseWriter discarding the response.
+type discardResponse struct {
+	header http.Header
+}
+
+func (w *discardResponse) Header() http.Header         { return w.header }
+func (w *discardResponse) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponse) WriteHeader(int)             {}
+
+// serve returns a function serving one request to a router using bc.
+func (bc benchCase) serve() func() {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(append([]Option{WithWriter(io.Discard)}, bc.opts...)...))
+	r.POST("/users/:id", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/plain", []byte("all good"))
+	})
+
+	req, _ := http.NewRequest("POST", "/users/42?page=1", nil)
+	var body rewindBody
+	if bc.body != "" {
+		body = rewindBody{bytes.NewReader([]byte(bc.body))}
+		req.Body = body
+		req.ContentLength = int64(len(bc.body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := &discardResponse{header: http.Header{}}
+	return func() {
+		if body.Reader != nil {
+			_, _ = body.Seek(0, io.SeekStart)
+		}
+		r.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkSetLogger(b *testing.B) {
+	for _, bc := range benchCases {
+		b.Run(bc.name, func(b *testing.B) {
+			serve := bc.serve()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				serve()
+			}
+		})
+	}
+}
+
+// TestAllocBudget fails when a change makes the hot path allocate more than the
+// budget of a configuration, catching performance regressions in CI.
+func TestAllocBudget(t *testing.T) {
+	if testing.Short() || raceEnabled {
+		t.Skip("allocation counts are not measured in short mode or with the race detector")
+	}
+	for _, bc := range benchCases {
+		t.Run(bc.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, bc.serve())
+			if allocs > bc.allocs {
+				t.Errorf("%v allocations per request, budget is %v", allocs, bc.allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	c, _ := gin.CreateTestContext(nil)
+	Set(c, zerolog.Nop())
+	b.Run("value", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l := Get(c)
+			l.Debug().Msg("")
+		}
+	})
+	b.Run("pointer", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			GetPtr(c).Debug().Msg("")
+		}
+	})
+}
+
+// trafficMix returns requests mixing methods and user agents, as served to a
+// typical API.
+func trafficMix() []*http.Request {
+	agents := []string{
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148",
+		"kube-probe/1.30",
+		"Go-http-client/1.1",
+	}
+	methods := []string{"GET", "POST", "PUT", "DELETE"}
+	reqs := make([]*http.Request, 0, len(methods)*len(agents))
+	for _, m := range methods {
+		for _, ua := range agents {
+			req, _ := http.NewRequest(m, "/users/42", nil)
+			req.Header.Set("User-Agent", ua)
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs
+}
+
+// BenchmarkTrafficMix serves a realistic mix of methods and user agents. With
+// a Formatter, the interned method, route and user agent of decoded events
+// reuse cached strings, see BenchmarkDecodeParams.
+func BenchmarkTrafficMix(b *testing.B) {
+	formats := []struct {
+		name string
+		opts []Option
+	}{
+		{"json", []Option{WithAutoFormat()}},
+		{"gin", []Option{WithGinFormat()}},
+	}
+	for _, f := range formats {
+		b.Run(f.name, func(b *testing.B) {
+			gin.SetMode(gin.ReleaseMode)
+			r := gin.New()
+			r.Use(SetLogger(append([]Option{WithWriter(io.Discard)}, f.opts...)...))
+			r.Any("/users/:id", func(c *gin.Context) {})
+			reqs := trafficMix()
+			w := &discardResponse{header: http.Header{}}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.ServeHTTP(w, reqs[i%len(reqs)])
+			}
+		})
+	}
+}
+
+// trafficEvent is an access event of the traffic mix, as decoded by Formatters.
+var trafficEvent = []byte(`{"level":"info","route":"/users/:id","status":200,"method":"GET","path":"/users/42",` +
+	`"ip":"10.0.0.1","latency":0.1,"user_agent":"kube-probe/1.30","body_size":8,` +
+	`"time":"2026-10-16T12:00:00Z","message":"Request"}` + "\n")
+
+// decodeEvent decodes p with a json.Decoder, as decodeParams does for the
+// events it cannot scan.
+func decodeEvent(p []byte) {
+	fields := map[string]any{}
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	_ = d.Decode(&fields)
+}
+
+func BenchmarkDecodeParams(b *testing.B) {
+	for _, s := range []string{"GET", "/users/:id", "kube-probe/1.30"} {
+		interned.intern(s)
+	}
+	b.Run("interned", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = decodeParams(trafficEvent)
+		}
+	})
+	b.Run("decoder", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			decodeEvent(trafficEvent)
+		}
+	})
+}
+
+// TestDecodeParamsAllocs checks that decoding a flat event with interned values
+// allocates less than half of what a json.Decoder does.
+func TestDecodeParamsAllocs(t *testing.T) {
+	if testing.Short() || raceEnabled {
+		t.Skip("allocation counts are not measured in short mode or with the race detector")
+	}
+	for _, s := range []string{"GET", "/users/:id", "kube-probe/1.30"} {
+		interned.intern(s)
+	}
+	_, _ = decodeParams(trafficEvent)
+	scanned := testing.AllocsPerRun(100, func() { _, _ = decodeParams(trafficEvent) })
+	decoded := testing.AllocsPerRun(100, func() { decodeEvent(trafficEvent) })
+	if scanned*2 > decoded {
+		t.Errorf("%v allocations per event, %v with a json.Decoder", scanned, decoded)
+	}
+}
+
+// TestStaticFieldsAllocs checks that static fields are encoded once, not per request.
+func TestStaticFieldsAllocs(t *testing.T) {
+	if testing.Short() || raceEnabled {
+		t.Skip("allocation counts are not measured in short mode or with the race detector")
+	}
+	plain := benchCase{opts: []Option{WithAutoFormat()}}
+	static := benchCase{opts: []Option{WithAutoFormat(), WithStaticFields(ServiceFields("api", "1.2.3"))}}
+	assert.Equal(t, testing.AllocsPerRun(100, plain.serve()), testing.AllocsPerRun(100, static.serve()))
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// maxBodyBytes is the largest request body the logger reads into memory.
+// Larger bodies are left untouched and not inspected.
+const maxBodyBytes = 1 << 20
+
+// readBody reads the request body and replaces it with an in-memory copy so the
+// handler can still consume it. It returns false when there is no body or it is
+// larger than maxBodyBytes.
+func readBody(c *gin.Context) ([]byte, bool) {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil, false
+	}
+	if c.Request.ContentLength > maxBodyBytes {
+		return nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodyBytes+1))
+	rest := c.Request.Body
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), rest), rest}
+	if err != nil || len(body) > maxBodyBytes {
+		return nil, false
+	}
+	return body, true
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// isJSON reports whether the request declares a JSON content type,
+// including structured syntax suffixes such as application/problem+json.
+func isJSON(c *gin.Context) bool {
+	ct := c.ContentType()
+	return ct == gin.MIMEJSON || strings.HasSuffix(ct, "+json")
+}
+
+// bodyFields extracts the values at the given dot separated paths from a JSON
+// body. Path segments are object keys or array indexes, e.g. "items.0.id".
+// Paths that do not resolve are omitted, and values are scrubbed by redactors
+// under the last segment of their path.
+func bodyFields(body []byte, paths []string, redactors []Redactor) *zerolog.Event {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil
+	}
+
+	var dict *zerolog.Event
+	for _, path := range paths {
+		v, ok := lookupPath(doc, path)
+		if !ok {
+			continue
+		}
+		if dict == nil {
+			dict = zerolog.Dict()
+		}
+		key := path[strings.LastIndexByte(path, '.')+1:]
+		dict = dict.Interface(path, redactValue(redactors, key, v))
+	}
+	return dict
+}
+
+// lookupPath walks doc along a dot separated path.
+func lookupPath(doc any, path string) (any, bool) {
+	cur := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// HashAlgorithm selects the digest used by WithBodyHash.
+type HashAlgorithm int
+
+const (
+	// HashSHA256 logs the hex encoded SHA-256 of the body.
+	HashSHA256 HashAlgorithm = iota
+	// HashXXHash logs the hex encoded 64-bit xxHash of the body. It is much
+	// faster than SHA-256 but not collision resistant against an attacker.
+	HashXXHash
+)
+
+func (a HashAlgorithm) new() hash.Hash {
+	if a == HashXXHash {
+		return xxhash.New()
+	}
+	return sha256.New()
+}
+
+// hashingReader hashes the request body as the handler reads it.
+type hashingReader struct {
+	io.ReadCloser
+	h   hash.Hash
+	eof bool
+}
+
+func newHashingReader(body io.ReadCloser, alg HashAlgorithm) *hashingReader {
+	return &hashingReader{ReadCloser: body, h: alg.new()}
+}
+
+func (r *hashingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.h.Write(p[:n])
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+// sum drains up to maxBodyBytes the handler left unread and returns the digest
+// of the whole body. It returns false if the body could not be read to the end.
+func (r *hashingReader) sum() (string, bool) {
+	if !r.eof {
+		_, _ = io.Copy(io.Discard, io.LimitReader(r, maxBodyBytes))
+	}
+	if !r.eof {
+		return "", false
+	}
+	return hex.EncodeToString(r.h.Sum(nil)), true
+}
@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func performBodyRequest(r http.Handler, method, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestLoggerBodyFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithBodyFields("user.id", "order.total", "items.1.sku", "missing")))
+	r.POST("/order", func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(b))
+	})
+
+	payload := `{"user":{"id":42,"email":"a@b.c"},"order":{"total":"9.90"},"items":[{"sku":"a"},{"sku":"b"}]}`
+	resp := performBodyRequest(r, "POST", "/order", "application/json; charset=utf-8", payload)
+	assert.Equal(t, payload, resp.Body.String())
+	assert.Contains(t, buffer.String(), `body={"items.1.sku":"b","order.total":"9.90","user.id":42}`)
+	assert.NotContains(t, buffer.String(), "a@b.c")
+	assert.NotContains(t, buffer.String(), "missing")
+
+	buffer.Reset()
+	resp = performBodyRequest(r, "POST", "/order", "text/plain", payload)
+	assert.Equal(t, payload, resp.Body.String())
+	assert.NotContains(t, buffer.String(), "body=")
+
+	buffer.Reset()
+	performBodyRequest(r, "POST", "/order", "application/json", "{invalid")
+	assert.NotContains(t, buffer.String(), "body=")
+}
+
+func TestReadBodyTooLarge(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	payload := strings.Repeat("x", maxBodyBytes+10)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(payload))
+	c.Request.ContentLength = -1
+
+	_, ok := readBody(c)
+	assert.False(t, ok)
+	b, _ := io.ReadAll(c.Request.Body)
+	assert.Equal(t, payload, string(b))
+}
+
+func TestLoggerBodyHash(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.POST("/sha", SetLogger(WithWriter(buffer), WithBodyHash(HashSHA256, true)), func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, strings.ToUpper(string(b)))
+	})
+	r.POST("/xx", SetLogger(WithWriter(buffer), WithBodyHash(HashXXHash, false)), func(c *gin.Context) {
+		c.String(http.StatusOK, "unread")
+	})
+
+	reqSum := sha256.Sum256([]byte("secret"))
+	respSum := sha256.Sum256([]byte("SECRET"))
+	performBodyRequest(r, "POST", "/sha", "text/plain", "secret")
+	assert.Contains(t, buffer.String(), "body_hash="+hex.EncodeToString(reqSum[:]))
+	assert.Contains(t, buffer.String(), "response_body_hash="+hex.EncodeToString(respSum[:]))
+	assert.NotContains(t, buffer.String(), "secret")
+
+	buffer.Reset()
+	performBodyRequest(r, "POST", "/xx", "text/plain", "secret")
+	assert.Contains(t, buffer.String(), fmt.Sprintf("body_hash=%016x", xxhash.Sum64String("secret")))
+	assert.NotContains(t, buffer.String(), "response_body_hash")
+}
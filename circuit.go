@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// CircuitState is the state of a CircuitBreakerWriter.
+type CircuitState int
+
+const (
+	// CircuitClosed sends events to the primary writer.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen sends events to the fallback writer until the backoff elapsed.
+	CircuitOpen
+	// CircuitHalfOpen tries the primary writer again with a single event.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerWriter protects request latency from a failing remote log sink
+// such as syslog, Loki or Kafka. After a number of consecutive failed writes the
+// circuit opens: the primary writer is not attempted for the backoff period and
+// events go to the fallback writer instead. Once the backoff elapsed, the next
+// event probes the primary writer and closes the circuit again on success.
+//
+// Every state change is reported once, as a JSON notice written to the fallback
+// writer, instead of one error per request.
+type CircuitBreakerWriter struct {
+	primary  io.Writer
+	fallback io.Writer
+	failures int
+	backoff  time.Duration
+	notice   zerolog.Logger
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    CircuitState
+	failed   int
+	openedAt time.Time
+}
+
+// NewCircuitBreakerWriter returns a CircuitBreakerWriter writing to primary and
+// falling back to fallback after failures consecutive errors, for backoff.
+func NewCircuitBreakerWriter(primary, fallback io.Writer, failures int, backoff time.Duration) *CircuitBreakerWriter {
+	if failures < 1 {
+		failures = 1
+	}
+	return &CircuitBreakerWriter{
+		primary:  primary,
+		fallback: fallback,
+		failures: failures,
+		backoff:  backoff,
+		notice:   zerolog.New(fallback).With().Timestamp().Logger(),
+		now:      time.Now,
+	}
+}
+
+// State returns the current state of the circuit.
+func (w *CircuitBreakerWriter) State() CircuitState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// Write writes p to the primary writer, or to the fallback writer while the circuit is open.
+func (w *CircuitBreakerWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.state == CircuitOpen {
+		if w.now().Sub(w.openedAt) < w.backoff {
+			return w.fallback.Write(p)
+		}
+		w.setState(CircuitHalfOpen, nil)
+	}
+
+	n, err := w.primary.Write(p)
+	if err == nil {
+		w.failed = 0
+		if w.state == CircuitHalfOpen {
+			w.setState(CircuitClosed, nil)
+		}
+		return n, nil
+	}
+
+	w.failed++
+	if w.state == CircuitHalfOpen || w.failed >= w.failures {
+		w.openedAt = w.now()
+		w.setState(CircuitOpen, err)
+	}
+	return w.fallback.Write(p)
+}
+
+func (w *CircuitBreakerWriter) setState(s CircuitState, err error) {
+	if w.state == s {
+		return
+	}
+	w.state = s
+	if s == CircuitHalfOpen {
+		return
+	}
+	evt := w.notice.Warn()
+	if s == CircuitClosed {
+		evt = w.notice.Info()
+	}
+	if err != nil {
+		evt = evt.Err(err).Dur("backoff", w.backoff)
+	}
+	evt.Str("circuit", s.String()).Msg("log sink circuit " + s.String())
+}
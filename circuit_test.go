@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyWriter fails while down is true.
+type flakyWriter struct {
+	down   bool
+	writes int
+	buf    bytes.Buffer
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+	f.writes++
+	if f.down {
+		return 0, errors.New("connection refused")
+	}
+	return f.buf.Write(p)
+}
+
+func TestCircuitBreakerWriter(t *testing.T) {
+	primary := &flakyWriter{down: true}
+	fallback := new(bytes.Buffer)
+	now := time.Now()
+	w := NewCircuitBreakerWriter(primary, fallback, 2, time.Minute)
+	w.now = func() time.Time { return now }
+
+	_, err := w.Write([]byte("a\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitClosed, w.State())
+
+	_, _ = w.Write([]byte("b\n"))
+	assert.Equal(t, CircuitOpen, w.State())
+	assert.Equal(t, 1, strings.Count(fallback.String(), "log sink circuit open"))
+
+	_, _ = w.Write([]byte("c\n"))
+	assert.Equal(t, 2, primary.writes)
+	assert.Contains(t, fallback.String(), "c\n")
+
+	// The probe after the backoff fails and opens the circuit again.
+	now = now.Add(time.Minute)
+	_, _ = w.Write([]byte("d\n"))
+	assert.Equal(t, 3, primary.writes)
+	assert.Equal(t, CircuitOpen, w.State())
+	assert.Equal(t, 2, strings.Count(fallback.String(), "log sink circuit open"))
+
+	primary.down = false
+	now = now.Add(time.Minute)
+	_, _ = w.Write([]byte("e\n"))
+	assert.Equal(t, CircuitClosed, w.State())
+	assert.Equal(t, "e\n", primary.buf.String())
+	assert.Contains(t, fallback.String(), "log sink circuit closed")
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultClickHouseColumns maps the columns of the table to the fields of the
+// access line when ClickHouseConfig.Columns is empty.
+var defaultClickHouseColumns = map[string]string{
+	"time":       zerolog.TimestampFieldName,
+	"level":      zerolog.LevelFieldName,
+	"message":    zerolog.MessageFieldName,
+	"method":     "method",
+	"path":       "path",
+	"status":     "status",
+	"latency":    "latency",
+	"ip":         "ip",
+	"user_agent": "user_agent",
+	"body_size":  "body_size",
+}
+
+// ClickHouseConfig configures the writer returned by NewClickHouseWriter.
+type ClickHouseConfig struct {
+	// DB is the database to insert into. When nil, the writer opens DSN with the
+	// "clickhouse" driver, which the application registers by importing
+	// github.com/ClickHouse/clickhouse-go/v2.
+	DB *sql.DB
+	// DSN is the data source name of the database, used when DB is nil.
+	DSN string
+	// Table is the table to insert into.
+	Table string
+	// Columns maps the columns of the table to the top-level fields of the
+	// events. It defaults to the time, level, message, method, path, status,
+	// latency, ip, user_agent and body_size columns filled with the fields of
+	// the same name.
+	Columns map[string]string
+	// MaxEvents, MaxBytes and FlushInterval are the limits of the batches, as
+	// for NewBatchWriter.
+	MaxEvents     int
+	MaxBytes      int
+	FlushInterval time.Duration
+}
+
+// NewClickHouseWriter returns a BatchWriter inserting the events into a
+// ClickHouse table, one batch per INSERT, in a transaction as clickhouse-go
+// expects for batches sent through database/sql. Missing fields are inserted
+// as NULL. The timestamp field is inserted as a time.Time, numbers as int64 or
+// float64, and objects and arrays as JSON text.
+//
+// Events must be JSON objects, one per line, which is what zerolog produces
+// when it is not wrapped in a ConsoleWriter, e.g. with WithAutoFormat. Other
+// lines are skipped and counted as dropped by Health, in the pipeline writing
+// to the writer with WithWriter, or as standalone. Call Close on the writer
+// during shutdown to insert the last batch.
+func NewClickHouseWriter(cfg ClickHouseConfig) (*BatchWriter, error) {
+	if !sqlIdentifier.MatchString(cfg.Table) {
+		return nil, fmt.Errorf("logger: invalid table name %q", cfg.Table)
+	}
+	mapping := cfg.Columns
+	if len(mapping) == 0 {
+		mapping = defaultClickHouseColumns
+	}
+	ins := &clickHouseInserter{db: cfg.DB, health: health}
+	for column := range mapping {
+		if !sqlIdentifier.MatchString(column) {
+			return nil, fmt.Errorf("logger: invalid column name %q", column)
+		}
+		ins.columns = append(ins.columns, column)
+	}
+	sort.Strings(ins.columns)
+	for _, column := range ins.columns {
+		ins.fields = append(ins.fields, mapping[column])
+	}
+	ins.query = `INSERT INTO ` + cfg.Table + ` (` + strings.Join(ins.columns, `, `) + `)`
+	if ins.db == nil {
+		db, err := sql.Open("clickhouse", cfg.DSN)
+		if err != nil {
+			return nil, err
+		}
+		ins.db = db
+	}
+	return NewBatchWriter(ins, cfg.MaxEvents, cfg.MaxBytes, cfg.FlushInterval), nil
+}
+
+// clickHouseInserter inserts the newline-delimited events written to it in a
+// single batch. It is only written to by its BatchWriter, whose lock guards
+// health.
+type clickHouseInserter struct {
+	db      *sql.DB
+	columns []string
+	fields  []string
+	query   string
+	health  *pipelineHealth
+}
+
+// reportTo makes the inserter count skipped lines as drops of h.
+func (ins *clickHouseInserter) reportTo(h *pipelineHealth) {
+	ins.health = h
+}
+
+func (ins *clickHouseInserter) Write(p []byte) (int, error) {
+	tx, err := ins.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	if err := ins.insert(tx, p); err != nil {
+		return 0, errors.Join(err, tx.Rollback())
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (ins *clickHouseInserter) insert(tx *sql.Tx, p []byte) error {
+	stmt, err := tx.Prepare(ins.query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	lines := bufio.NewScanner(bytes.NewReader(p))
+	lines.Buffer(nil, len(p)+1)
+	for lines.Scan() {
+		if len(bytes.TrimSpace(lines.Bytes())) == 0 {
+			continue
+		}
+		fields := map[string]any{}
+		d := json.NewDecoder(bytes.NewReader(lines.Bytes()))
+		d.UseNumber()
+		if err := d.Decode(&fields); err != nil {
+			// A line that is not JSON, such as a console formatted or truncated
+			// event, must not cost the valid events of the batch.
+			ins.health.recordDrop()
+			continue
+		}
+		values := make([]any, len(ins.fields))
+		for i, name := range ins.fields {
+			values[i] = clickHouseValue(name, fields[name])
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+	return lines.Err()
+}
+
+// clickHouseValue converts the decoded value v of the field name for insertion.
+func clickHouseValue(name string, v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if name == zerolog.TimestampFieldName {
+			if t, err := time.Parse(zerolog.TimeFieldFormat, v); err == nil {
+				return t
+			}
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case bool:
+		return v
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
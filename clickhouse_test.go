@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClickHouseWriter(t *testing.T) {
+	db, f := openFakeDB(t)
+	_, err := NewClickHouseWriter(ClickHouseConfig{DB: db, Table: "logs", Columns: map[string]string{"a b": "status"}})
+	assert.Error(t, err)
+
+	w, err := NewClickHouseWriter(ClickHouseConfig{
+		DB:        db,
+		Table:     "access_logs",
+		Columns:   map[string]string{"ts": "time", "status": "status", "latency_ms": "latency", "tags": "tags", "route": "route"},
+		MaxEvents: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = w.Write([]byte(`{"time":"2024-01-02T03:04:05Z","status":200,"latency":1.5,"tags":["a"]}` + "\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, f.statements("INSERT"))
+
+	_, err = w.Write([]byte(`{"status":404,"route":"/users/:id"}` + "\n"))
+	assert.NoError(t, err)
+	inserts := f.statements("INSERT INTO access_logs (latency_ms, route, status, tags, ts)")
+	if assert.Len(t, inserts, 2) {
+		assert.Equal(t, []driver.Value{1.5, nil, int64(200), `["a"]`, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}, inserts[0].args)
+		assert.Equal(t, []driver.Value{nil, "/users/:id", int64(404), nil, nil}, inserts[1].args)
+	}
+	assert.Len(t, f.statements("BEGIN"), 1)
+	assert.Len(t, f.statements("COMMIT"), 1)
+
+	// A line that is not JSON is dropped without the rest of its batch.
+	dropped := Health().DroppedEvents
+	_, err = w.Write([]byte("GET / 200\n"))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(`{"status":201}` + "\n"))
+	assert.NoError(t, err)
+	assert.Len(t, f.statements("COMMIT"), 2)
+	assert.Empty(t, f.statements("ROLLBACK"))
+	assert.Equal(t, dropped+1, Health().DroppedEvents)
+	inserts = f.statements("INSERT INTO access_logs")
+	if assert.Len(t, inserts, 3) {
+		assert.Equal(t, int64(201), inserts[2].args[2])
+	}
+}
+
+func TestLoggerClickHouseWriterHealth(t *testing.T) {
+	db, f := openFakeDB(t)
+	w, err := NewClickHouseWriter(ClickHouseConfig{DB: db, Table: "access_logs", MaxEvents: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without WithAutoFormat the events are console formatted, not JSON.
+	p, err := NewPipeline(WithName("clickhouse"), WithWriter(w))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(p.Handler())
+	r.GET("/example", func(c *gin.Context) {})
+	performRequest(r, "GET", "/example")
+
+	standalone := health.dropped.Load()
+	assert.NoError(t, w.Flush())
+	assert.Len(t, f.statements("COMMIT"), 1)
+	assert.Equal(t, int64(1), p.Health().DroppedEvents)
+	assert.Equal(t, standalone, health.dropped.Load())
+	assert.NoError(t, p.Close())
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// latencyKey is the context key the computed latency is stored under.
+const latencyKey = "_gin-contrib/logger_latency_"
+
+// maxLatency is the latency above which a measurement is considered bogus.
+const maxLatency = 7 * 24 * time.Hour
+
+// suspectLatency reports whether latency is negative or absurdly long, which
+// happens when a Clock without monotonic readings jumps, e.g. on wall clock
+// adjustments or after the host resumed from hibernation.
+func suspectLatency(latency time.Duration) bool {
+	return latency < 0 || latency > maxLatency
+}
+
+// Clock provides the current time to the middleware. Injecting a fake clock
+// with WithClock makes latency and time fields deterministic in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock reads the system clock. time.Now carries a monotonic reading, so
+// latencies computed from it are immune to wall clock adjustments.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// requestStartKey is the context key the arrival time of the request is stored
+// under when events are timestamped at the start of the request.
+const requestStartKey = "_gin-contrib/logger_request_start_"
+
+// TimestampAt selects the time the request log event is timestamped with.
+type TimestampAt int
+
+const (
+	// TimestampAtEnd timestamps events when they are logged, so the request log
+	// event carries the completion time of the request. This is the default.
+	TimestampAtEnd TimestampAt = iota
+	// TimestampAtStart timestamps events logged with the request context, such as
+	// the request log event, with the arrival time of the request.
+	TimestampAtStart
+)
+
+// clockHook sets the timestamp of every event from a Clock, in place of
+// zerolog's global TimestampFunc. When atStart is set, events carrying a request
+// context are timestamped with the arrival time of the request instead.
+type clockHook struct {
+	clock   Clock
+	atStart bool
+}
+
+func (h clockHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	if h.atStart {
+		if start, ok := e.GetCtx().Value(requestStartKey).(time.Time); ok {
+			e.Time(zerolog.TimestampFieldName, start)
+			return
+		}
+	}
+	e.Time(zerolog.TimestampFieldName, h.clock.Now())
+}
+
+// Latency returns the latency of a logged request computed by SetLogger. It is set
+// once the handler chain returned, so it is available to middleware registered
+// before SetLogger after their call to c.Next().
+func Latency(c *gin.Context) (time.Duration, bool) {
+	v, ok := c.Get(latencyKey)
+	if !ok {
+		return 0, false
+	}
+	latency, ok := v.(time.Duration)
+	return latency, ok
+}
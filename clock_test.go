@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stepClock advances by step every time it is read.
+type stepClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func (s *stepClock) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now
+	s.now = s.now.Add(s.step)
+	return now
+}
+
+func TestLoggerWithClock(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), step: 250 * time.Millisecond}
+	var latency time.Duration
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		latency, _ = Latency(c)
+	})
+	r.Use(SetLogger(WithWriter(buffer), WithClock(clock)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Equal(t, 250*time.Millisecond, latency)
+	assert.Contains(t, buffer.String(), "latency=250")
+	assert.Contains(t, buffer.String(), time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Local().Format(time.Kitchen))
+}
+
+func TestLatencyNotSet(t *testing.T) {
+	c, _ := gin.CreateTestContext(nil)
+	_, ok := Latency(c)
+	assert.False(t, ok)
+}
+
+func TestLoggerTimestampAt(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	arrival := start.Local().Format(time.Kitchen)
+	for _, at := range []TimestampAt{TimestampAtEnd, TimestampAtStart} {
+		buffer := new(bytes.Buffer)
+		clock := &stepClock{now: start, step: time.Hour}
+		gin.SetMode(gin.ReleaseMode)
+		r := gin.New()
+		r.Use(SetLogger(WithWriter(buffer), WithClock(clock), WithTimestampAt(at)))
+		r.GET("/example", func(c *gin.Context) {})
+
+		performRequest(r, "GET", "/example")
+		if at == TimestampAtStart {
+			assert.True(t, strings.HasPrefix(buffer.String(), arrival))
+		} else {
+			assert.False(t, strings.HasPrefix(buffer.String(), arrival))
+		}
+	}
+}
+
+func TestLoggerLatencySuspect(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	// A clock stepping backwards, as a wall clock does when adjusted.
+	clock := &stepClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), step: -time.Minute}
+	var latency time.Duration
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Next()
+		latency, _ = Latency(c)
+	})
+	r.Use(SetLogger(WithWriter(buffer), WithClock(clock)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "latency_suspect=true")
+	assert.NotContains(t, buffer.String(), "latency=")
+	assert.Equal(t, time.Duration(0), latency)
+
+	assert.False(t, suspectLatency(time.Hour))
+	assert.True(t, suspectLatency(maxLatency+1))
+}
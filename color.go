@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ANSI colors for ConsoleColors.
+const (
+	ColorBlack = iota + 30
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
+
+// ConsoleColors configures the color of the level and message of console lines,
+// see WithConsoleColors. A line takes the color of its status class first, then
+// SlowColor when the request took at least Slow, then the color of its level.
+// Lines matching no rule keep zerolog's default colors.
+type ConsoleColors struct {
+	// Levels maps log levels to colors.
+	Levels map[zerolog.Level]int
+	// StatusClasses maps status classes (2 for 2xx, 5 for 5xx, ...) to colors.
+	StatusClasses map[int]int
+	// Slow is the latency from which a request is colored with SlowColor; zero disables it.
+	Slow time.Duration
+	// SlowColor is the color of slow requests.
+	SlowColor int
+}
+
+// DefaultConsoleColors returns 5xx in red, 4xx in yellow and requests slower
+// than a second in magenta.
+func DefaultConsoleColors() *ConsoleColors {
+	return &ConsoleColors{
+		StatusClasses: map[int]int{4: ColorYellow, 5: ColorRed},
+		Slow:          time.Second,
+		SlowColor:     ColorMagenta,
+	}
+}
+
+// pick returns the color of the event, or zero when no rule matches.
+func (cc *ConsoleColors) pick(evt map[string]any) int {
+	if cc == nil || evt == nil {
+		return 0
+	}
+	if status, ok := number(evt["status"]); ok {
+		if color, ok := cc.StatusClasses[int(status)/100]; ok {
+			return color
+		}
+	}
+	if latency, ok := number(evt["latency"]); ok && cc.Slow > 0 {
+		if time.Duration(latency*float64(zerolog.DurationFieldUnit)) >= cc.Slow {
+			return cc.SlowColor
+		}
+	}
+	if level, ok := evt[zerolog.LevelFieldName].(string); ok {
+		if l, err := zerolog.ParseLevel(level); err == nil {
+			if color, ok := cc.Levels[l]; ok {
+				return color
+			}
+		}
+	}
+	return 0
+}
+
+// colorWriter is the console writer used when WithConsoleColors is set.
+type colorWriter struct {
+	out     io.Writer
+	noColor bool
+	colors  *ConsoleColors
+}
+
+// Write formats the JSON event p with the colors picked for it.
+func (w colorWriter) Write(p []byte) (int, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		evt = nil
+	}
+	return newConsoleWriter(w.out, w.noColor, w.colors, evt).Write(p)
+}
+
+// newConsoleWriter returns a zerolog.ConsoleWriter writing to out whose level
+// and message are colored according to colors for the decoded event evt.
+func newConsoleWriter(out io.Writer, noColor bool, colors *ConsoleColors, evt map[string]any) zerolog.ConsoleWriter {
+	cw := zerolog.ConsoleWriter{Out: out, NoColor: noColor}
+	color := colors.pick(evt)
+	if noColor || color == 0 {
+		return cw
+	}
+	cw.FormatLevel = func(i any) string {
+		s, _ := i.(string)
+		l, err := zerolog.ParseLevel(s)
+		if fl, ok := zerolog.FormattedLevels[l]; ok && err == nil {
+			s = fl
+		}
+		return colorize(s, color, false)
+	}
+	cw.FormatMessage = func(i any) string {
+		if i == nil {
+			return ""
+		}
+		return colorize(fmt.Sprint(i), color, false)
+	}
+	return cw
+}
+
+// number returns v as a float64 when it is a decoded JSON number.
+func number(v any) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(n.String(), 64)
+	return f, err == nil
+}
+
+// colorize wraps s in the ANSI color c unless disabled.
+func colorize(s string, c int, disabled bool) string {
+	if disabled {
+		return s
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c, s)
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleColors(t *testing.T) {
+	cc := DefaultConsoleColors()
+	cc.Levels = map[zerolog.Level]int{zerolog.DebugLevel: ColorBlue}
+
+	tests := []struct {
+		name  string
+		event string
+		want  string
+	}{
+		{"server error", `{"level":"error","status":503,"latency":2000}`, colorize("ERR", ColorRed, false)},
+		{"client error", `{"level":"warn","status":404,"latency":1}`, colorize("WRN", ColorYellow, false)},
+		{"slow", `{"level":"info","status":200,"latency":1500.5}`, colorize("INF", ColorMagenta, false)},
+		{"level", `{"level":"debug","status":200,"latency":1}`, colorize("DBG", ColorBlue, false)},
+		{"no rule", `{"level":"info","status":200,"latency":1}`, colorize("INF", ColorGreen, false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			_, err := colorWriter{out: buffer, colors: cc}.Write([]byte(tt.event))
+			assert.NoError(t, err)
+			assert.Contains(t, buffer.String(), tt.want)
+		})
+	}
+}
+
+func TestConsoleColorsDisabled(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	_, err := colorWriter{out: buffer, noColor: true, colors: DefaultConsoleColors()}.
+		Write([]byte(`{"level":"error","status":500,"message":"Request"}`))
+	assert.NoError(t, err)
+	assert.NotContains(t, buffer.String(), "\x1b[")
+	assert.Contains(t, buffer.String(), "ERR Request")
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrOptionConflict is wrapped by the errors New returns for options that
+// cannot be combined.
+var ErrOptionConflict = errors.New("logger: conflicting options")
+
+// ErrOptionWarning is wrapped by the errors New returns for options that can be
+// combined but may ignore one another, depending on what the functions they
+// are given do. New still returns the middleware when there are only warnings.
+var ErrOptionWarning = errors.New("logger: options may conflict")
+
+// conflicts returns the options of the configuration that cannot be combined,
+// and those that may ignore one another, joined in a single error.
+func (cfg *config) conflicts() error {
+	var errs []error
+	conflict := func(options, reason string) {
+		errs = append(errs, fmt.Errorf("%w: %s: %s", ErrOptionConflict, options, reason))
+	}
+	warning := func(options, reason string) {
+		errs = append(errs, fmt.Errorf("%w: %s: %s", ErrOptionWarning, options, reason))
+	}
+
+	if cfg.output != os.Stderr && cfg.logger != nil {
+		warning("WithWriter and WithLogger", "a logger returned with another output replaces the writer")
+	}
+	if cfg.skip != nil && len(cfg.pathLevels) > 0 {
+		warning("WithSkipper and WithPathLevel", "requests skipped by the Skipper are not logged, whatever their path level")
+	}
+
+	for _, path := range cfg.skipPath {
+		if _, ok := cfg.pathLevels[path]; ok {
+			conflict("WithSkipPath and WithPathLevel", fmt.Sprintf("%q is skipped, so its level is never used", path))
+		}
+	}
+	if cfg.sampler != nil && cfg.errorsOnly() {
+		conflict("WithSampler and WithDefaultLevel", "only errors are logged and errors are never sampled")
+	}
+	if cfg.formatter != nil && cfg.ginFormat {
+		conflict("WithFormatter and WithGinFormat", "only one formatter can be used")
+	}
+	if (cfg.formatter != nil || cfg.ginFormat) && (cfg.devFormat || cfg.consoleColors != nil) {
+		conflict("WithFormatter and console format options", "the formatter replaces the console format")
+	}
+	if cfg.synchronous && (cfg.asyncQueue > 0 || cfg.batch != nil || cfg.resolveHost || len(cfg.crashSignals) > 0) {
+		conflict("WithSynchronous and WithAsync, WithBatching, WithResolveClientHost or WithCrashDump signals",
+			"they run goroutines, so they are disabled")
+	}
+	return errors.Join(errs...)
+}
+
+// errorsOnly reports whether successful requests are never logged: their level
+// is disabled or below the global level, whatever the path.
+func (cfg *config) errorsOnly() bool {
+	if cfg.controller != nil {
+		// The levels can change at runtime.
+		return false
+	}
+	enabled := func(lvl zerolog.Level) bool {
+		return lvl != zerolog.Disabled && lvl >= zerolog.GlobalLevel()
+	}
+	if enabled(cfg.defaultLevel) {
+		return false
+	}
+	for _, lvl := range cfg.pathLevels {
+		if enabled(lvl) {
+			return false
+		}
+	}
+	return true
+}
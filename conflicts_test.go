@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConflicts(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	enrich := WithLogger(func(c *gin.Context, l zerolog.Logger) zerolog.Logger {
+		return l.With().Str("path", c.Request.URL.Path).Logger()
+	})
+	skipper := WithSkipper(func(c *gin.Context) bool {
+		return c.Request.URL.Path == "/health"
+	})
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		kind     error
+		conflict string
+	}{
+		{"none", []Option{WithWriter(buffer)}, nil, ""},
+		{"logger without writer", []Option{enrich}, nil, ""},
+		{"skip and level", []Option{
+			WithSkipPath([]string{"/health"}),
+			WithPathLevel(map[string]zerolog.Level{"/health": zerolog.DebugLevel}),
+		}, ErrOptionConflict, `"/health" is skipped`},
+		{"sampling errors only", []Option{
+			WithSampler(RandomSampler(0.5, 1)),
+			WithDefaultLevel(zerolog.Disabled),
+		}, ErrOptionConflict, "WithSampler"},
+		{"sampling with a path level", []Option{
+			WithSampler(RandomSampler(0.5, 1)),
+			WithDefaultLevel(zerolog.Disabled),
+			WithPathLevel(map[string]zerolog.Level{"/api": zerolog.InfoLevel}),
+		}, nil, ""},
+		{"formatters", []Option{WithFormatter(GinFormatter(false)), WithGinFormat()}, ErrOptionConflict, "only one formatter"},
+		{"formatter and console", []Option{WithGinFormat(), WithDevFormat()}, ErrOptionConflict, "replaces the console format"},
+		{"writer and logger", []Option{WithWriter(buffer), enrich}, ErrOptionWarning, "WithWriter and WithLogger"},
+		{"skipper and path level", []Option{
+			skipper,
+			WithPathLevel(map[string]zerolog.Level{"/api": zerolog.DebugLevel}),
+		}, ErrOptionWarning, "WithSkipper and WithPathLevel"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := New(tt.opts...)
+			switch tt.kind {
+			case nil:
+				assert.NoError(t, err)
+				assert.NotNil(t, h)
+			case ErrOptionWarning:
+				assert.True(t, errors.Is(err, ErrOptionWarning))
+				assert.False(t, errors.Is(err, ErrOptionConflict))
+				assert.ErrorContains(t, err, tt.conflict)
+				assert.NotNil(t, h)
+			default:
+				assert.True(t, errors.Is(err, ErrOptionConflict))
+				assert.ErrorContains(t, err, tt.conflict)
+				assert.Nil(t, h)
+			}
+		})
+	}
+}
+
+func TestNewSamplingBelowGlobalLevel(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+
+	_, err := New(WithSampler(RandomSampler(0.5, 1)))
+	assert.True(t, errors.Is(err, ErrOptionConflict))
+	assert.ErrorContains(t, err, "only errors are logged")
+}
+
+func TestNewReportsEveryConflict(t *testing.T) {
+	_, err := New(
+		WithSkipPath([]string{"/a", "/b"}),
+		WithPathLevel(map[string]zerolog.Level{"/a": zerolog.DebugLevel, "/b": zerolog.DebugLevel}),
+	)
+	assert.ErrorContains(t, err, `"/a"`)
+	assert.ErrorContains(t, err, `"/b"`)
+}
@@ -0,0 +1,298 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+// consoleField is a top-level member of an event: its key and its raw JSON value.
+type consoleField struct {
+	key   []byte
+	value []byte
+	// str reports whether value is a JSON string, without its quotes.
+	str bool
+}
+
+// consoleState holds the buffers a plainConsoleWriter reuses across events.
+type consoleState struct {
+	buf    []byte
+	fields []consoleField
+}
+
+var consolePool = sync.Pool{
+	New: func() any {
+		return &consoleState{buf: make([]byte, 0, 512), fields: make([]consoleField, 0, 16)}
+	},
+}
+
+// plainConsoleWriter writes events in the format of a zerolog.ConsoleWriter
+// without colors. It scans the event in place with pooled buffers instead of
+// decoding it into a map, which saves most of the allocations of the console
+// format. Events it does not handle byte for byte like the ConsoleWriter, such
+// as events with escaped strings, nested values or a caller, are passed to the
+// ConsoleWriter.
+type plainConsoleWriter struct {
+	out  io.Writer
+	slow zerolog.ConsoleWriter
+}
+
+func newPlainConsoleWriter(out io.Writer) plainConsoleWriter {
+	return plainConsoleWriter{out: out, slow: zerolog.ConsoleWriter{Out: out, NoColor: true}}
+}
+
+func (w plainConsoleWriter) Write(p []byte) (int, error) {
+	st := consolePool.Get().(*consoleState)
+	defer consolePool.Put(st)
+
+	var ok bool
+	if st.fields, ok = scanEvent(p, st.fields[:0]); !ok {
+		return w.slow.Write(p)
+	}
+	if st.buf, ok = appendConsole(st.buf[:0], st.fields); !ok {
+		return w.slow.Write(p)
+	}
+	_, err := w.out.Write(st.buf)
+	return len(p), err
+}
+
+// scanEvent appends the members of the JSON object p to fields. It reports false
+// for anything but a flat object of unescaped strings, numbers and literals.
+func scanEvent(p []byte, fields []consoleField) ([]consoleField, bool) {
+	p = bytes.TrimRight(p, "\n")
+	if len(p) < 2 || p[0] != '{' || p[len(p)-1] != '}' {
+		return fields, false
+	}
+	i := 1
+	if p[i] == '}' {
+		return fields, i == len(p)-1
+	}
+	for {
+		key, next, ok := scanString(p, i)
+		if !ok || next >= len(p) || p[next] != ':' {
+			return fields, false
+		}
+		i = next + 1
+		if i >= len(p) {
+			return fields, false
+		}
+		f := consoleField{key: key}
+		if p[i] == '"' {
+			f.value, next, ok = scanString(p, i)
+			f.str = true
+		} else {
+			next = i
+			for next < len(p) && p[next] != ',' && p[next] != '}' {
+				next++
+			}
+			f.value = p[i:next]
+			ok = next > i && scalar(f.value)
+		}
+		if !ok || next >= len(p) {
+			return fields, false
+		}
+		fields = append(fields, f)
+		switch p[next] {
+		case ',':
+			i = next + 1
+		case '}':
+			return fields, next == len(p)-1
+		default:
+			return fields, false
+		}
+	}
+}
+
+// scanString returns the content of the JSON string starting at p[i] and the
+// index following it. It reports false for escaped or invalid UTF-8 strings,
+// which a JSON decoder would rewrite.
+func scanString(p []byte, i int) ([]byte, int, bool) {
+	if i >= len(p) || p[i] != '"' {
+		return nil, 0, false
+	}
+	end := bytes.IndexByte(p[i+1:], '"')
+	if end < 0 {
+		return nil, 0, false
+	}
+	s := p[i+1 : i+1+end]
+	if bytes.IndexByte(s, '\\') >= 0 || !utf8.Valid(s) {
+		return nil, 0, false
+	}
+	return s, i + end + 2, true
+}
+
+// scalar reports whether v is a JSON number or literal, printed as is.
+func scalar(v []byte) bool {
+	switch string(v) {
+	case "true", "false", "null":
+		return true
+	}
+	if v[0] != '-' && (v[0] < '0' || v[0] > '9') {
+		return false
+	}
+	for _, b := range v {
+		if (b < '0' || b > '9') && b != '-' && b != '+' && b != '.' && b != 'e' && b != 'E' {
+			return false
+		}
+	}
+	return true
+}
+
+// appendConsole appends the console line of the event fields to buf, as the
+// ConsoleWriter formats it without colors: timestamp, level and message, then
+// the other fields sorted by key with the error field first.
+func appendConsole(buf []byte, fields []consoleField) ([]byte, bool) {
+	var ts, level, msg consoleField
+	var hasTS, hasLevel, hasMsg bool
+	rest := fields[:0]
+	for _, f := range fields {
+		switch string(f.key) {
+		case zerolog.TimestampFieldName:
+			ts, hasTS = f, true
+		case zerolog.LevelFieldName:
+			level, hasLevel = f, true
+		case zerolog.MessageFieldName:
+			msg, hasMsg = f, true
+		case zerolog.CallerFieldName:
+			return buf, false
+		default:
+			rest = append(rest, f)
+		}
+	}
+	sortFields(rest)
+	for i := 1; i < len(rest); i++ {
+		if bytes.Equal(rest[i].key, rest[i-1].key) {
+			return buf, false
+		}
+	}
+
+	switch {
+	case !hasTS:
+		buf = append(buf, "<nil>"...)
+	case !ts.str || len(ts.value) == 0:
+		return buf, false
+	default:
+		if t, err := time.ParseInLocation(zerolog.TimeFieldFormat, string(ts.value), time.Local); err == nil {
+			buf = t.In(time.Local).AppendFormat(buf, time.Kitchen)
+		} else {
+			buf = append(buf, ts.value...)
+		}
+	}
+
+	buf = append(buf, ' ')
+	switch {
+	case !hasLevel:
+		buf = append(buf, "???"...)
+	case !level.str:
+		return buf, false
+	default:
+		buf = appendLevel(buf, level.value)
+	}
+
+	if hasMsg {
+		if !msg.str {
+			return buf, false
+		}
+		if len(msg.value) > 0 {
+			buf = append(buf, ' ')
+			buf = append(buf, msg.value...)
+		}
+	}
+
+	if len(rest) > 0 {
+		buf = append(buf, ' ')
+	}
+	if ei := errorField(rest); ei > 0 {
+		e := rest[ei]
+		copy(rest[1:ei+1], rest[:ei])
+		rest[0] = e
+	}
+	for i, f := range rest {
+		buf = append(buf, f.key...)
+		buf = append(buf, '=')
+		if f.str && needsQuote(f.value) {
+			buf = strconv.AppendQuote(buf, string(f.value))
+		} else {
+			buf = append(buf, f.value...)
+		}
+		if i < len(rest)-1 {
+			buf = append(buf, ' ')
+		}
+	}
+	return append(buf, '\n'), true
+}
+
+// appendLevel appends the console abbreviation of the level value v to buf.
+func appendLevel(buf []byte, v []byte) []byte {
+	l, ok := levelOf(v)
+	if !ok {
+		l, _ = zerolog.ParseLevel(string(v))
+	}
+	if fl, ok := zerolog.FormattedLevels[l]; ok {
+		return append(buf, fl...)
+	}
+	if len(v) == 0 {
+		return append(buf, "???"...)
+	}
+	if len(v) > 3 {
+		v = v[:3]
+	}
+	return append(buf, strings.ToUpper(string(v))...)
+}
+
+// levelOf returns the level named v without converting v to a string.
+func levelOf(v []byte) (zerolog.Level, bool) {
+	switch string(v) {
+	case zerolog.LevelTraceValue:
+		return zerolog.TraceLevel, true
+	case zerolog.LevelDebugValue:
+		return zerolog.DebugLevel, true
+	case zerolog.LevelInfoValue:
+		return zerolog.InfoLevel, true
+	case zerolog.LevelWarnValue:
+		return zerolog.WarnLevel, true
+	case zerolog.LevelErrorValue:
+		return zerolog.ErrorLevel, true
+	case zerolog.LevelFatalValue:
+		return zerolog.FatalLevel, true
+	case zerolog.LevelPanicValue:
+		return zerolog.PanicLevel, true
+	}
+	return zerolog.NoLevel, false
+}
+
+// errorField returns the index of the error field in the sorted fields, or -1.
+func errorField(fields []consoleField) int {
+	for i, f := range fields {
+		if string(f.key) == zerolog.ErrorFieldName {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortFields sorts fields by key. Events have few fields, so an insertion sort
+// avoids the allocations of the sort package.
+func sortFields(fields []consoleField) {
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && bytes.Compare(fields[j].key, fields[j-1].key) < 0; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+}
+
+// needsQuote reports whether the console format quotes the string value v.
+func needsQuote(v []byte) bool {
+	for _, b := range v {
+		if b < 0x20 || b > 0x7e || b == ' ' || b == '\\' || b == '"' {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainConsoleWriterMatchesConsoleWriter(t *testing.T) {
+	events := []string{
+		`{}`,
+		`{"level":"info","time":"2024-01-02T03:04:05Z","message":"Request"}`,
+		`{"level":"warn","status":404,"method":"GET","path":"/a b","ip":"192.0.2.1","latency":0.25,"user_agent":"curl/8.0","time":"2024-01-02T03:04:05Z","message":"Request"}` + "\n",
+		`{"level":"error","error":"boom","zeta":true,"alpha":null,"time":"2024-01-02T03:04:05Z","message":""}`,
+		`{"level":"debug","path":"/café","time":"not a time","message":"handler"}`,
+		`{"level":"custom","n":-1.5e3,"time":"2024-01-02T03:04:05Z"}`,
+		`{"level":"","message":"no time"}`,
+		`{"time":"2024-01-02T03:04:05Z","message":"no level","key":""}`,
+		`{"level":"info","time":"2024-01-02T03:04:05Z","message":"tab\tescaped","q":"say \"hi\""}`,
+		`{"level":"info","time":"2024-01-02T03:04:05Z","dict":{"b":1,"a":2},"list":[1,"x"]}`,
+		`{"level":"info","time":"2024-01-02T03:04:05Z","caller":"logger.go:1","message":"caller"}`,
+		`{"level":"info","time":"2024-01-02T03:04:05Z","dup":1,"dup":2}`,
+		`{"level":1,"time":1704164645,"message":3}`,
+	}
+	for _, e := range events {
+		want, got := new(bytes.Buffer), new(bytes.Buffer)
+		_, wantErr := zerolog.ConsoleWriter{Out: want, NoColor: true}.Write([]byte(e))
+		_, gotErr := newPlainConsoleWriter(got).Write([]byte(e))
+		assert.Equal(t, want.String(), got.String(), e)
+		assert.Equal(t, wantErr, gotErr, e)
+	}
+}
+
+func TestPlainConsoleWriterFastPath(t *testing.T) {
+	fields, ok := scanEvent([]byte(`{"level":"info","path":"/a","status":200}`), nil)
+	assert.True(t, ok)
+	assert.Len(t, fields, 3)
+
+	_, ok = scanEvent([]byte(`{"q":"say \"hi\""}`), nil)
+	assert.False(t, ok)
+	_, ok = scanEvent([]byte(`{"dict":{"a":1}}`), nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Controller reconfigures running middleware set up with WithController, e.g.
+// from an admin endpoint or on a configuration reload, without restarting them.
+// The zero value is ready to use and leaves the configuration unchanged.
+//
+// Settings are published as immutable snapshots through an atomic pointer:
+// requests load the current snapshot once, when they start, and never take a
+// lock. A request therefore uses the settings of a single snapshot from start
+// to end, never a mix of old and new ones, and every request starting after a
+// setter returned sees its change. Setters are serialized with each other.
+type Controller struct {
+	mu       sync.Mutex
+	snapshot atomic.Pointer[controlSnapshot]
+}
+
+// controlSnapshot is a published, immutable set of settings of a Controller.
+type controlSnapshot struct {
+	// hasLevels reports whether the levels replace those of the options.
+	hasLevels                                        bool
+	defaultLevel, clientErrorLevel, serverErrorLevel zerolog.Level
+	// matcher replaces the skip and only rules of the options when set.
+	matcher *Matcher
+}
+
+// NewController returns a Controller leaving the configuration unchanged until
+// one of its setters is called.
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// SetLevels replaces the levels set with WithDefaultLevel, WithClientErrorLevel
+// and WithServerErrorLevel.
+func (ctl *Controller) SetLevels(defaultLevel, clientErrorLevel, serverErrorLevel zerolog.Level) {
+	ctl.update(func(s *controlSnapshot) {
+		s.hasLevels = true
+		s.defaultLevel, s.clientErrorLevel, s.serverErrorLevel = defaultLevel, clientErrorLevel, serverErrorLevel
+	})
+}
+
+// SetMatcher replaces the skip and only rules of the options with m. A nil m
+// restores them.
+func (ctl *Controller) SetMatcher(m *Matcher) {
+	ctl.update(func(s *controlSnapshot) {
+		s.matcher = m
+	})
+}
+
+// Reset restores the configuration of the options.
+func (ctl *Controller) Reset() {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	ctl.snapshot.Store(nil)
+}
+
+// update publishes a copy of the current snapshot modified by fn.
+func (ctl *Controller) update(fn func(*controlSnapshot)) {
+	ctl.mu.Lock()
+	defer ctl.mu.Unlock()
+	var next controlSnapshot
+	if cur := ctl.snapshot.Load(); cur != nil {
+		next = *cur
+	}
+	fn(&next)
+	ctl.snapshot.Store(&next)
+}
+
+// load returns the current snapshot, or nil when there are no changes.
+func (ctl *Controller) load() *controlSnapshot {
+	if ctl == nil {
+		return nil
+	}
+	return ctl.snapshot.Load()
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestController(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	ctl := NewController()
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithController(ctl), WithSkipPath([]string{"/health"})))
+	r.GET("/example", func(c *gin.Context) {})
+	r.GET("/health", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "INF")
+
+	buffer.Reset()
+	ctl.SetLevels(zerolog.WarnLevel, zerolog.WarnLevel, zerolog.ErrorLevel)
+	ctl.SetMatcher(NewMatcher(WithSkipPath([]string{"/example"})))
+	performRequest(r, "GET", "/example")
+	performRequest(r, "GET", "/health")
+	assert.NotContains(t, buffer.String(), "/example")
+	assert.Contains(t, buffer.String(), "WRN")
+	assert.Contains(t, buffer.String(), "path=/health")
+
+	buffer.Reset()
+	ctl.Reset()
+	performRequest(r, "GET", "/example")
+	performRequest(r, "GET", "/health")
+	assert.Contains(t, buffer.String(), "INF")
+	assert.NotContains(t, buffer.String(), "/health")
+}
+
+// TestControllerConcurrentUpdates serves requests while the settings change;
+// run with -race to check that requests read snapshots without data races.
+func TestControllerConcurrentUpdates(t *testing.T) {
+	ctl := &Controller{}
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(io.Discard), WithController(ctl)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/example", nil))
+			}
+		}()
+	}
+	for j := 0; j < 200; j++ {
+		ctl.SetLevels(zerolog.Level(j%3), zerolog.WarnLevel, zerolog.ErrorLevel)
+		ctl.SetMatcher(NewMatcher(WithSkipPath([]string{"/other"})))
+	}
+	wg.Wait()
+
+	snap := ctl.load()
+	assert.True(t, snap.hasLevels)
+	assert.NotNil(t, snap.matcher)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"math/rand"
+	"runtime"
+	"sync/atomic"
+)
+
+// maxCounterShards bounds the memory of a shardedCounter on large machines.
+const maxCounterShards = 32
+
+// counterShards is the number of shards of a shardedCounter: the smallest power
+// of two covering GOMAXPROCS at startup, at most maxCounterShards.
+var counterShards = func() int {
+	n := 1
+	for n < runtime.GOMAXPROCS(0) && n < maxCounterShards {
+		n <<= 1
+	}
+	return n
+}()
+
+// counterShard is a cell of a shardedCounter padded to its own cache line, so
+// concurrent increments of different shards do not contend.
+type counterShard struct {
+	v atomic.Int64
+	_ [56]byte
+}
+
+// shardedCounter is a counter spreading concurrent increments over several
+// cache lines. Go does not expose the current CPU, so increments pick a shard at
+// random, which keeps goroutines on different CPUs apart most of the time.
+// Reads sum the shards and are slower than increments.
+type shardedCounter struct {
+	shards []counterShard
+}
+
+func newShardedCounter() *shardedCounter {
+	return &shardedCounter{shards: make([]counterShard, counterShards)}
+}
+
+// Add adds delta to the counter.
+func (s *shardedCounter) Add(delta int64) {
+	s.shards[rand.Uint32()&uint32(len(s.shards)-1)].v.Add(delta)
+}
+
+// Load returns the sum of the increments.
+func (s *shardedCounter) Load() int64 {
+	var sum int64
+	for i := range s.shards {
+		sum += s.shards[i].v.Load()
+	}
+	return sum
+}
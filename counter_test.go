@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedCounter(t *testing.T) {
+	c := newShardedCounter()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Add(2)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(16000), c.Load())
+	assert.Equal(t, 0, len(c.shards)&(len(c.shards)-1))
+}
+
+// mutexCounter is the naive counter the sharded counter is compared against.
+type mutexCounter struct {
+	mu sync.Mutex
+	v  int64
+}
+
+func (m *mutexCounter) Add(delta int64) {
+	m.mu.Lock()
+	m.v += delta
+	m.mu.Unlock()
+}
+
+// BenchmarkCounterContention increments a counter from all Ps at once; compare
+// the results with -cpu 1,4,16.
+func BenchmarkCounterContention(b *testing.B) {
+	b.Run("mutex", func(b *testing.B) {
+		var c mutexCounter
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Add(1)
+			}
+		})
+	})
+	b.Run("atomic", func(b *testing.B) {
+		var c atomic.Int64
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Add(1)
+			}
+		})
+	})
+	b.Run("sharded", func(b *testing.B) {
+		c := newShardedCounter()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				c.Add(1)
+			}
+		})
+	})
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ringWriter keeps the most recent events in memory so they can be dumped to a
+// crash file. It receives the JSON events before console formatting and before
+// any asynchronous queue, so it holds events that were not written yet.
+type ringWriter struct {
+	path string
+
+	mu     sync.Mutex
+	events [][]byte
+	next   int
+	full   bool
+}
+
+func newRingWriter(path string, size int) *ringWriter {
+	if size < 1 {
+		size = 1
+	}
+	return &ringWriter{path: path, events: make([][]byte, size)}
+}
+
+func (r *ringWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[r.next] = append(r.events[r.next][:0], p...)
+	r.next = (r.next + 1) % len(r.events)
+	if r.next == 0 {
+		r.full = true
+	}
+	return len(p), nil
+}
+
+// dump appends the buffered events, oldest first, to the crash file, followed
+// by a line describing the reason of the dump.
+func (r *ringWriter) dump(reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	start := 0
+	if r.full {
+		start = r.next
+	}
+	for i := 0; i < len(r.events); i++ {
+		evt := r.events[(start+i)%len(r.events)]
+		if len(evt) == 0 {
+			continue
+		}
+		if _, err = f.Write(evt); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	l := zerolog.New(f)
+	l.WithLevel(zerolog.PanicLevel).
+		Str("reason", reason).
+		Time(zerolog.TimestampFieldName, time.Now()).
+		Msg("crash dump")
+	return f.Close()
+}
+
+var (
+	ringsMu sync.Mutex
+	rings   []*ringWriter
+)
+
+// DumpCrash writes the recent events kept by every SetLogger instance configured
+// with WithCrashDump to their crash files. Applications handling termination
+// signals themselves can call it from their shutdown path.
+func DumpCrash(reason string) error {
+	ringsMu.Lock()
+	defer ringsMu.Unlock()
+
+	var errs []error
+	for _, r := range rings {
+		errs = append(errs, r.dump(reason))
+	}
+	return errors.Join(errs...)
+}
+
+// registerRing makes r part of DumpCrash and dumps it when one of sigs is
+// received, until the returned function is called. The signal is then raised
+// again with the handler removed, so the process terminates as it would have
+// without the logger.
+func registerRing(r *ringWriter, sigs []os.Signal) func() {
+	ringsMu.Lock()
+	rings = append(rings, r)
+	ringsMu.Unlock()
+
+	stop := make(chan struct{})
+	if len(sigs) > 0 {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, sigs...)
+		go func() {
+			select {
+			case sig := <-ch:
+				_ = r.dump(fmt.Sprintf("signal: %s", sig))
+				signal.Stop(ch)
+				if p, err := os.FindProcess(os.Getpid()); err == nil {
+					_ = p.Signal(sig)
+				}
+			case <-stop:
+				signal.Stop(ch)
+			}
+		}()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			ringsMu.Lock()
+			defer ringsMu.Unlock()
+			for i, q := range rings {
+				if q == r {
+					rings = append(rings[:i], rings[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
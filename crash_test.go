@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingWriterDump(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash.log")
+	r := newRingWriter(path, 2)
+	for _, evt := range []string{"{\"n\":1}\n", "{\"n\":2}\n", "{\"n\":3}\n"} {
+		_, _ = r.Write([]byte(evt))
+	}
+
+	assert.NoError(t, r.dump("test"))
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, `{"n":2}`, lines[0])
+	assert.Equal(t, `{"n":3}`, lines[1])
+	assert.Contains(t, lines[2], `"reason":"test"`)
+}
+
+func TestLoggerCrashDumpOnPanic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash.log")
+	out := new(countingWriter)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(500)
+	}))
+	p, err := NewPipeline(WithWriter(out), WithBatching(100, 0, 0), WithCrashDump(path, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Closing the pipeline keeps its ring out of the DumpCrash of other tests.
+	t.Cleanup(func() { _ = p.Close() })
+	r.Use(p.Handler())
+	r.GET("/ok", func(c *gin.Context) {})
+	r.GET("/panic", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("about to fail")
+		panic("boom")
+	})
+
+	performRequest(r, "GET", "/ok")
+	resp := performRequest(r, "GET", "/panic")
+	assert.Equal(t, 500, resp.Code)
+	assert.Equal(t, 0, out.count())
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"path":"/ok"`)
+	assert.Contains(t, string(b), "about to fail")
+	assert.Contains(t, string(b), `"reason":"panic: boom"`)
+
+	assert.NoError(t, DumpCrash("shutdown"))
+	b, _ = os.ReadFile(path)
+	assert.Contains(t, string(b), `"reason":"shutdown"`)
+}
+
+func TestPipelineCloseUnregistersRing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crash.log")
+	p, err := NewPipeline(WithWriter(io.Discard), WithCrashDump(path, 10, os.Interrupt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ringsMu.Lock()
+	n := len(rings)
+	ringsMu.Unlock()
+
+	assert.NoError(t, p.Close())
+	ringsMu.Lock()
+	assert.Equal(t, n-1, len(rings))
+	ringsMu.Unlock()
+	assert.NoError(t, DumpCrash("closed"))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"net"
+	"sync/atomic"
+)
+
+// maxUDPPayload is the largest payload of a UDP datagram over IPv4.
+const maxUDPPayload = 65507
+
+// DatagramPolicy decides what a DatagramWriter does with an event larger than
+// its maximum datagram size.
+type DatagramPolicy int
+
+const (
+	// DatagramTruncate sends the first bytes of the event, up to the maximum
+	// size. The collector receives an incomplete JSON object it may reject.
+	DatagramTruncate DatagramPolicy = iota
+	// DatagramDrop discards the event.
+	DatagramDrop
+)
+
+// DatagramWriter ships each event as a single UDP or unix datagram packet to a
+// local collector, fire and forget: there is no connection to keep alive, no
+// acknowledgement and no retry, so a collector that is down loses events
+// instead of slowing requests down. Events must be written one per Write call,
+// which is what zerolog does.
+type DatagramWriter struct {
+	conn    net.Conn
+	maxSize int
+	policy  DatagramPolicy
+
+	truncated atomic.Int64
+	dropped   atomic.Int64
+}
+
+// NewDatagramWriter returns a DatagramWriter sending to addr over network,
+// "udp", "udp4", "udp6" or "unixgram". Events larger than maxSize bytes are
+// handled according to policy; a maxSize below 1 means the largest UDP payload,
+// 65507 bytes.
+func NewDatagramWriter(network, addr string, maxSize int, policy DatagramPolicy) (*DatagramWriter, error) {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize < 1 {
+		maxSize = maxUDPPayload
+	}
+	return &DatagramWriter{conn: conn, maxSize: maxSize, policy: policy}, nil
+}
+
+// Write sends the event p as one datagram. Errors of the socket, such as the
+// collector refusing the packet, are returned; wrap the writer in a
+// CircuitBreakerWriter to fall back to another writer while it fails.
+func (w *DatagramWriter) Write(p []byte) (int, error) {
+	packet := p
+	if len(packet) > w.maxSize {
+		if w.policy == DatagramDrop {
+			w.dropped.Add(1)
+			return len(p), nil
+		}
+		w.truncated.Add(1)
+		packet = packet[:w.maxSize]
+	}
+	if _, err := w.conn.Write(packet); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Truncated returns the number of events sent truncated with DatagramTruncate.
+func (w *DatagramWriter) Truncated() int64 {
+	return w.truncated.Load()
+}
+
+// Dropped returns the number of events discarded with DatagramDrop.
+func (w *DatagramWriter) Dropped() int64 {
+	return w.dropped.Load()
+}
+
+// Close closes the socket.
+func (w *DatagramWriter) Close() error {
+	return w.conn.Close()
+}
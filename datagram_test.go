@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readPacket reads one datagram from pc.
+func readPacket(t *testing.T, pc net.PacketConn) string {
+	t.Helper()
+	buf := make([]byte, 1024)
+	assert.NoError(t, pc.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestDatagramWriterUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewDatagramWriter("udp", pc.LocalAddr().String(), 16, DatagramTruncate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	n, err := w.Write([]byte(`{"a":1}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 8, n)
+	assert.Equal(t, `{"a":1}`+"\n", readPacket(t, pc))
+
+	n, err = w.Write([]byte(`{"message":"too long"}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 23, n)
+	assert.Equal(t, `{"message":"too `, readPacket(t, pc))
+	assert.Equal(t, int64(1), w.Truncated())
+}
+
+func TestDatagramWriterDrop(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unixgram sockets are not supported on windows")
+	}
+	addr := filepath.Join(t.TempDir(), "collector.sock")
+	pc, err := net.ListenPacket("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewDatagramWriter("unixgram", addr, 16, DatagramDrop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	_, err = w.Write([]byte(`{"message":"too long"}` + "\n"))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(`{"a":1}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`+"\n", readPacket(t, pc))
+	assert.Equal(t, int64(1), w.Dropped())
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// coreDependencies are the modules the middleware may require directly.
+// Integrations needing other modules belong in a separate module or behind a
+// build tag, see the Dependencies section of the README.
+var coreDependencies = []string{
+	"github.com/cespare/xxhash/v2",
+	"github.com/gin-gonic/gin",
+	"github.com/mattn/go-isatty",
+	"github.com/rs/zerolog",
+	"github.com/stretchr/testify",
+	"golang.org/x/text",
+}
+
+func TestCoreDependencies(t *testing.T) {
+	b, err := os.ReadFile("go.mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var direct []string
+	inRequire := false
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "require (":
+			inRequire = true
+		case line == ")":
+			inRequire = false
+		case strings.HasPrefix(line, "require ") && !strings.HasSuffix(line, "("):
+			line = strings.TrimPrefix(line, "require ")
+			fallthrough
+		case inRequire && line != "":
+			if !strings.Contains(line, "// indirect") {
+				direct = append(direct, strings.Fields(line)[0])
+			}
+		}
+	}
+	assert.ElementsMatch(t, coreDependencies, direct)
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// devWriter is the multi-line console format enabled by WithDevFormat. It prints
+// the primary line like zerolog.ConsoleWriter, followed by every object or array
+// field of the event (errors, captured body, headers, ...) as an indented,
+// pretty-printed block.
+type devWriter struct {
+	out     io.Writer
+	noColor bool
+	colors  *ConsoleColors
+}
+
+// Write formats the JSON event p and writes it to the underlying writer in a single call.
+func (w devWriter) Write(p []byte) (int, error) {
+	var evt map[string]any
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return newConsoleWriter(w.out, w.noColor, nil, nil).Write(p)
+	}
+
+	var blocks []string
+	for k, v := range evt {
+		switch v.(type) {
+		case map[string]any, []any:
+			blocks = append(blocks, k)
+		}
+	}
+	sort.Strings(blocks)
+
+	buf := new(bytes.Buffer)
+	cw := newConsoleWriter(buf, w.noColor, w.colors, evt)
+	cw.FieldsExclude = blocks
+	if _, err := cw.Write(p); err != nil {
+		return 0, err
+	}
+	for _, k := range blocks {
+		color := ColorCyan
+		if k == "errors" {
+			color = ColorRed
+		}
+		b, err := json.MarshalIndent(evt[k], "    ", "  ")
+		if err != nil {
+			return 0, err
+		}
+		fmt.Fprintf(buf, "  %s\n    %s\n", colorize(k+":", color, w.noColor), b)
+	}
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
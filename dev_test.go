@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerDevFormat(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithDevFormat(), WithRouteParams(true)))
+	r.GET("/users/:id", func(c *gin.Context) {
+		Error(c, errors.New("boom"), "op", "load")
+		c.Status(500)
+	})
+
+	performRequest(r, "GET", "/users/42")
+	lines := strings.Split(buffer.String(), "\n")
+	assert.Contains(t, lines[0], "status=500")
+	assert.NotContains(t, lines[0], "errors=")
+	assert.NotContains(t, lines[0], "params=")
+	assert.Contains(t, buffer.String(), "\n  errors:\n    [\n      {\n")
+	assert.Contains(t, buffer.String(), `"error": "boom",`)
+	assert.Contains(t, buffer.String(), "\n  params:\n    {\n      \"id\": \"42\"\n    }\n")
+	assert.Less(t, strings.Index(buffer.String(), "errors:"), strings.Index(buffer.String(), "params:"))
+}
+
+func TestDevWriterPlainEvent(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	w := devWriter{out: buffer, noColor: true}
+	n, err := w.Write([]byte(`{"level":"info","message":"hi","a":1}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 38, n)
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"))
+	assert.Contains(t, buffer.String(), "hi a=1")
+}
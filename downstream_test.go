@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerCountDownstream(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithSkipPath([]string{"/health"})))
+	r.GET("/orders", func(c *gin.Context) {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				CountDownstream(c, "db", 5*time.Millisecond)
+			}()
+		}
+		wg.Wait()
+		CountDownstream(c, "cache", time.Millisecond)
+	})
+	r.GET("/plain", func(c *gin.Context) {})
+	r.GET("/health", func(c *gin.Context) {
+		CountDownstream(c, "db", time.Millisecond)
+	})
+
+	performRequest(r, "GET", "/orders")
+	assert.Contains(t, buffer.String(), "cache_calls=1 cache_time=1 db_calls=4 db_time=20")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/plain")
+	assert.NotContains(t, buffer.String(), "_calls")
+
+	// Calls of unlogged requests are ignored.
+	buffer.Reset()
+	performRequest(r, "GET", "/health")
+	assert.Empty(t, buffer.String())
+}
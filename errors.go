@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// errorsKey is the context key recorded errors are stored under.
+const errorsKey = "_gin-contrib/logger_errors_"
+
+// maxStackDepth is the number of frames captured by Error.
+const maxStackDepth = 32
+
+// recordedError is an error recorded by Error or Errorf.
+type recordedError struct {
+	err     error
+	stack   []string
+	keyvals []any
+}
+
+// Error records err on the request together with the stack of the caller and
+// optional key-value pairs, e.g. Error(c, err, "order_id", id). The completion
+// event emits every recorded error under "errors", keeping the context that
+// c.Error loses.
+func Error(c *gin.Context, err error, keyvals ...any) {
+	if err == nil {
+		return
+	}
+	recordError(c, err, keyvals)
+}
+
+// Errorf records an error formatted according to a format specifier, as Error does.
+func Errorf(c *gin.Context, format string, args ...any) {
+	recordError(c, fmt.Errorf(format, args...), nil)
+}
+
+func recordError(c *gin.Context, err error, keyvals []any) {
+	var recorded []recordedError
+	if v, ok := c.Get(errorsKey); ok {
+		recorded, _ = v.([]recordedError)
+	}
+	c.Set(errorsKey, append(recorded, recordedError{
+		err:     err,
+		stack:   callers(4),
+		keyvals: keyvals,
+	}))
+}
+
+// callers returns the stack of the caller, skip frames up, as "function file:line" entries.
+func callers(skip int) []string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	stack := make([]string, 0, n)
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame.Function+" "+frame.File+":"+strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// recordedErrors renders the errors recorded on the request, or nil if there are none.
+func recordedErrors(c *gin.Context) *zerolog.Array {
+	v, ok := c.Get(errorsKey)
+	if !ok {
+		return nil
+	}
+	recorded, _ := v.([]recordedError)
+	if len(recorded) == 0 {
+		return nil
+	}
+
+	arr := zerolog.Arr()
+	for _, r := range recorded {
+		arr = arr.Dict(zerolog.Dict().
+			Fields(r.keyvals).
+			Str("error", r.err.Error()).
+			Strs("stack", r.stack))
+	}
+	return arr
+}
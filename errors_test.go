@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerRecordedErrors(t *testing.T) {
+	out := new(countingWriter)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
+		return l.Output(out)
+	})))
+	r.GET("/example", func(c *gin.Context) {
+		Error(c, errors.New("payment declined"), "order_id", 42)
+		Errorf(c, "retry %d failed", 2)
+		Error(c, nil)
+		c.Status(http.StatusPaymentRequired)
+	})
+	r.GET("/ok", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Equal(t, 1, out.count())
+	assert.Contains(t, out.writes[0], `"errors":[{"order_id":42,"error":"payment declined","stack":["github.com/gin-contrib/logger.TestLoggerRecordedErrors.func2 `)
+	assert.Contains(t, out.writes[0], `{"error":"retry 2 failed","stack":[`)
+	assert.Contains(t, out.writes[0], "errors_test.go:")
+
+	performRequest(r, "GET", "/ok")
+	assert.NotContains(t, out.writes[1], `"errors"`)
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"expvar"
+	"io"
+	"sync"
+)
+
+// defaultExpvarPrefix is the name of the expvar map used by WithExpvar.
+const defaultExpvarPrefix = "gin_logger"
+
+var expvarMu sync.Mutex
+
+// expvarMap returns the expvar map published under name, creating it on first use.
+// SetLogger instances configured with the same prefix share their counters.
+func expvarMap(name string) *expvar.Map {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	if m, ok := expvar.Get(name).(*expvar.Map); ok {
+		return m
+	}
+	m := expvar.NewMap(name)
+	for _, key := range []string{"requests_total", "errors_total", "dropped_log_events", "bytes_written"} {
+		m.Add(key, 0)
+	}
+	return m
+}
+
+// expvarWriter counts the bytes written to the log output and the events that
+// could not be written.
+type expvarWriter struct {
+	w io.Writer
+	m *expvar.Map
+}
+
+func (e expvarWriter) Write(p []byte) (int, error) {
+	n, err := e.w.Write(p)
+	e.m.Add("bytes_written", int64(n))
+	if err != nil {
+		e.m.Add("dropped_log_events", 1)
+	}
+	return n, err
+}
@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"expvar"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingWriter is a log output whose writes always fail.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestLoggerExpvar(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/ok", SetLogger(WithWriter(new(bytes.Buffer)), WithExpvar(true), WithExpvarPrefix("test_logger")),
+		func(c *gin.Context) {})
+	r.GET("/fail", SetLogger(WithWriter(failingWriter{}), WithExpvar(true), WithExpvarPrefix("test_logger")),
+		func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	m := expvarMap("test_logger")
+	before := make(map[string]int64)
+	m.Do(func(kv expvar.KeyValue) {
+		before[kv.Key] = kv.Value.(*expvar.Int).Value()
+	})
+
+	performRequest(r, "GET", "/ok")
+	performRequest(r, "GET", "/fail")
+
+	delta := func(key string) int64 {
+		return m.Get(key).(*expvar.Int).Value() - before[key]
+	}
+	assert.Equal(t, int64(2), delta("requests_total"))
+	assert.Equal(t, int64(1), delta("errors_total"))
+	assert.Equal(t, int64(1), delta("dropped_log_events"))
+	assert.Positive(t, delta("bytes_written"))
+}
@@ -0,0 +1,367 @@
+package logger
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// compressionFields adds the response Content-Encoding and the cache outcome of
+// conditional requests to the event.
+//
+// When the response was compressed by a middleware registered after SetLogger
+// (e.g. gin-contrib/gzip), both the compressed size written to the client and the
+// size the handler produced are added, as long as the handler set the status before
+// writing the body, which gin's render helpers always do.
+func compressionFields(c *gin.Context, evt *zerolog.Event, w *responseWriter) *zerolog.Event {
+	if enc := c.Writer.Header().Get("Content-Encoding"); enc != "" {
+		evt = evt.Str("content_encoding", enc)
+		if w != nil && w.wrapped {
+			evt = evt.
+				Int("compressed_size", c.Writer.Size()).
+				Int("uncompressed_size", w.handlerSize)
+		}
+	}
+
+	if c.Writer.Status() == http.StatusNotModified {
+		etag := c.Writer.Header().Get("ETag")
+		evt = evt.Bool("etag_match", etag != "" && etagMatches(c.GetHeader("If-None-Match"), etag))
+	}
+	return evt
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag,
+// using the weak comparison function of RFC 9110.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionalFields adds a "conditional" group for requests carrying
+// If-None-Match or If-Modified-Since: which of them were sent, whether the
+// response was 304 Not Modified and, when an ETag was returned, whether it matched.
+func conditionalFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	ifNoneMatch := c.GetHeader("If-None-Match")
+	ifModifiedSince := c.GetHeader("If-Modified-Since")
+	if ifNoneMatch == "" && ifModifiedSince == "" {
+		return evt
+	}
+
+	dict := zerolog.Dict().
+		Bool("if_none_match", ifNoneMatch != "").
+		Bool("if_modified_since", ifModifiedSince != "").
+		Bool("not_modified", c.Writer.Status() == http.StatusNotModified)
+	if etag := c.Writer.Header().Get("ETag"); ifNoneMatch != "" && etag != "" {
+		dict = dict.Bool("etag_match", etagMatches(ifNoneMatch, etag))
+	}
+	return evt.Dict("conditional", dict)
+}
+
+// rangeFields adds the requested byte range, the Content-Range served and the
+// number of bytes served for range requests and 206 Partial Content responses.
+func rangeFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	requested := c.GetHeader("Range")
+	if requested == "" && c.Writer.Status() != http.StatusPartialContent {
+		return evt
+	}
+
+	if requested != "" {
+		evt = evt.Str("range", requested)
+	}
+	if served := c.Writer.Header().Get("Content-Range"); served != "" {
+		evt = evt.Str("content_range", served)
+	}
+	return evt.Int("bytes_served", c.Writer.Size())
+}
+
+// trailerFields adds the configured trailers of the request and the response.
+// It runs after the handler chain returned, once the request body was consumed
+// and the response body fully written, which is when trailers are available.
+func trailerFields(c *gin.Context, evt *zerolog.Event, names []string, redactors []Redactor) *zerolog.Event {
+	var req, resp *zerolog.Event
+	for _, name := range names {
+		if v := c.Request.Trailer.Get(name); v != "" {
+			if req == nil {
+				req = zerolog.Dict()
+			}
+			req = req.Str(name, redactHeader(redactors, name, v))
+		}
+
+		v := c.Writer.Header().Get(http.TrailerPrefix + name)
+		if v == "" && declaresTrailer(c.Writer.Header(), name) {
+			v = c.Writer.Header().Get(name)
+		}
+		if v != "" {
+			if resp == nil {
+				resp = zerolog.Dict()
+			}
+			resp = resp.Str(name, redactHeader(redactors, name, v))
+		}
+	}
+
+	if req != nil {
+		evt = evt.Dict("request_trailers", req)
+	}
+	if resp != nil {
+		evt = evt.Dict("response_trailers", resp)
+	}
+	return evt
+}
+
+// declaresTrailer reports whether the Trailer header of h announces name.
+func declaresTrailer(h http.Header, name string) bool {
+	for _, v := range h.Values("Trailer") {
+		for _, declared := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(declared), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// authUser returns the username of an Authorization: Basic header.
+// The password is never returned.
+func authUser(c *gin.Context) (string, bool) {
+	scheme, credentials, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Basic") {
+		return "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+	if err != nil {
+		return "", false
+	}
+	user, _, ok := strings.Cut(string(decoded), ":")
+	return user, ok
+}
+
+// rateLimitHeaders maps the "ratelimit" group keys to the response headers set by
+// rate limiting middleware, in order of preference: the de facto X-RateLimit-*
+// headers first, then the IETF RateLimit-* fields.
+var rateLimitHeaders = []struct {
+	key     string
+	headers []string
+}{
+	{"limit", []string{"X-RateLimit-Limit", "RateLimit-Limit"}},
+	{"remaining", []string{"X-RateLimit-Remaining", "RateLimit-Remaining"}},
+	{"reset", []string{"X-RateLimit-Reset", "RateLimit-Reset"}},
+	{"retry_after", []string{"Retry-After"}},
+}
+
+// rateLimitFields adds a "ratelimit" group with the rate limiting headers of the
+// response, when there are any or the request was rejected with 429 Too Many Requests.
+func rateLimitFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	var dict *zerolog.Event
+	for _, f := range rateLimitHeaders {
+		for _, name := range f.headers {
+			if v := c.Writer.Header().Get(name); v != "" {
+				if dict == nil {
+					dict = zerolog.Dict()
+				}
+				dict = dict.Str(f.key, v)
+				break
+			}
+		}
+	}
+
+	limited := c.Writer.Status() == http.StatusTooManyRequests
+	if dict == nil && !limited {
+		return evt
+	}
+	if dict == nil {
+		dict = zerolog.Dict()
+	}
+	return evt.Dict("ratelimit", dict.Bool("limited", limited))
+}
+
+// corsFields adds a "cors" group for requests carrying an Origin header: the
+// origin, whether the request is a preflight, and whether the response allowed
+// the origin through its Access-Control-Allow-Origin header.
+func corsFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	origin := c.GetHeader("Origin")
+	if origin == "" {
+		return evt
+	}
+
+	allowOrigin := c.Writer.Header().Get("Access-Control-Allow-Origin")
+	preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+	return evt.Dict("cors", zerolog.Dict().
+		Str("origin", origin).
+		Bool("preflight", preflight).
+		Bool("allowed", allowOrigin == "*" || allowOrigin == origin))
+}
+
+// negotiationFields adds a "negotiation" group with the requested and served
+// languages and encodings. Empty values are omitted.
+func negotiationFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	dict := zerolog.Dict()
+	empty := true
+	for _, f := range []struct{ key, value string }{
+		{"accept_language", c.GetHeader("Accept-Language")},
+		{"content_language", c.Writer.Header().Get("Content-Language")},
+		{"accept_encoding", c.GetHeader("Accept-Encoding")},
+		{"content_encoding", c.Writer.Header().Get("Content-Encoding")},
+	} {
+		if f.value != "" {
+			dict = dict.Str(f.key, f.value)
+			empty = false
+		}
+	}
+	if empty {
+		return evt
+	}
+	return evt.Dict("negotiation", dict)
+}
+
+// routeParamsFields adds the route parameters of the request as a "params" group,
+// restricted to allow when it is not empty. Values are scrubbed by redactors.
+func routeParamsFields(c *gin.Context, evt *zerolog.Event, allow map[string]struct{}, redactors []Redactor) *zerolog.Event {
+	if len(c.Params) == 0 {
+		return evt
+	}
+	var dict *zerolog.Event
+	for _, p := range c.Params {
+		if len(allow) > 0 {
+			if _, ok := allow[p.Key]; !ok {
+				continue
+			}
+		}
+		if dict == nil {
+			dict = zerolog.Dict()
+		}
+		dict = dict.Str(p.Key, redactString(redactors, p.Key, p.Value))
+	}
+	if dict == nil {
+		return evt
+	}
+	return evt.Dict("params", dict)
+}
+
+// envoyHeaderPrefix is the prefix of the headers Envoy adds to requests.
+const envoyHeaderPrefix = "X-Envoy-"
+
+// envoyFields adds the x-envoy-* headers of the request as an "envoy" group and
+// those of the response as an "envoy_response" group, e.g. x-envoy-attempt-count
+// as attempt_count, in the order of their names, so a header set on both sides
+// is logged twice instead of producing a duplicate key. Numeric values are
+// logged as numbers. The bulky x-envoy-peer-metadata header is left out.
+func envoyFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	if dict := envoyDict(c.Request.Header); dict != nil {
+		evt = evt.Dict("envoy", dict)
+	}
+	if dict := envoyDict(c.Writer.Header()); dict != nil {
+		evt = evt.Dict("envoy_response", dict)
+	}
+	return evt
+}
+
+// envoyDict returns the x-envoy-* headers of h, sorted by name, or nil if it
+// has none.
+func envoyDict(h http.Header) *zerolog.Event {
+	var names []string
+	for name, values := range h {
+		suffix, ok := strings.CutPrefix(name, envoyHeaderPrefix)
+		if ok && len(values) > 0 && suffix != "Peer-Metadata" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	dict := zerolog.Dict()
+	for _, name := range names {
+		key := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(name, envoyHeaderPrefix), "-", "_"))
+		value := h[name][0]
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			dict = dict.Int64(key, n)
+		} else {
+			dict = dict.Str(key, value)
+		}
+	}
+	return dict
+}
+
+// requestStart parses the X-Request-Start or X-Queue-Start header set by an
+// upstream proxy: a Unix timestamp, optionally prefixed with "t=", in seconds with
+// a fraction (nginx) or in whole seconds, milliseconds (Heroku), microseconds or
+// nanoseconds, told apart by magnitude.
+func requestStart(c *gin.Context) (time.Time, bool) {
+	v := c.GetHeader("X-Request-Start")
+	if v == "" {
+		v = c.GetHeader("X-Queue-Start")
+	}
+	v = strings.TrimPrefix(strings.TrimSpace(v), "t=")
+	if v == "" {
+		return time.Time{}, false
+	}
+	if sec, frac, ok := strings.Cut(v, "."); ok {
+		s, err := strconv.ParseInt(sec, 10, 64)
+		if err != nil || s <= 0 || len(frac) == 0 {
+			return time.Time{}, false
+		}
+		frac = (frac + "000000000")[:9]
+		ns, err := strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(s, ns), true
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	switch {
+	case err != nil || n <= 0:
+		return time.Time{}, false
+	case n >= 1e18:
+		return time.Unix(0, n), true
+	case n >= 1e15:
+		return time.UnixMicro(n), true
+	case n >= 1e12:
+		return time.UnixMilli(n), true
+	default:
+		return time.Unix(n, 0), true
+	}
+}
+
+// latencyBuckets holds the boundaries and labels of the latency_bucket field.
+type latencyBuckets struct {
+	bounds []time.Duration
+	labels []string
+}
+
+// newLatencyBuckets returns the buckets delimited by bounds, labelled like
+// "<10ms", "10ms-100ms" and ">1s".
+func newLatencyBuckets(bounds []time.Duration) *latencyBuckets {
+	bounds = append([]time.Duration(nil), bounds...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+	b := &latencyBuckets{bounds: bounds, labels: make([]string, len(bounds)+1)}
+	for i, bound := range bounds {
+		if i == 0 {
+			b.labels[i] = "<" + bound.String()
+		} else {
+			b.labels[i] = bounds[i-1].String() + "-" + bound.String()
+		}
+	}
+	b.labels[len(bounds)] = ">" + bounds[len(bounds)-1].String()
+	return b
+}
+
+// bucket returns the label of the bucket latency falls in.
+func (b *latencyBuckets) bucket(latency time.Duration) string {
+	i := sort.Search(len(b.bounds), func(i int) bool { return latency < b.bounds[i] })
+	return b.labels[i]
+}
@@ -0,0 +1,477 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type gzipTestWriter struct {
+	gin.ResponseWriter
+	writer *gzip.Writer
+}
+
+func (g *gzipTestWriter) WriteString(s string) (int, error) {
+	return g.writer.Write([]byte(s))
+}
+
+func (g *gzipTestWriter) Write(data []byte) (int, error) {
+	return g.writer.Write(data)
+}
+
+// gzipTestMiddleware mimics gin-contrib/gzip.
+func gzipTestMiddleware(c *gin.Context) {
+	gz := gzip.NewWriter(c.Writer)
+	c.Header("Content-Encoding", "gzip")
+	c.Writer = &gzipTestWriter{c.Writer, gz}
+	defer gz.Close()
+	c.Next()
+}
+
+func TestLoggerCompressionFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithCompressionFields(true)))
+	body := strings.Repeat("compressible ", 100)
+	r.GET("/gzip", gzipTestMiddleware, func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	})
+	r.GET("/plain", func(c *gin.Context) {
+		c.String(http.StatusOK, body)
+	})
+	r.GET("/etag", func(c *gin.Context) {
+		c.Header("ETag", `"v1"`)
+		if c.GetHeader("If-None-Match") == `"v1"` {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+
+	resp := performRequest(r, "GET", "/gzip")
+	assert.Contains(t, buffer.String(), "content_encoding=gzip")
+	assert.Contains(t, buffer.String(), "uncompressed_size="+strconv.Itoa(len(body)))
+	assert.Contains(t, buffer.String(), "compressed_size="+strconv.Itoa(resp.Body.Len()))
+
+	buffer.Reset()
+	performRequest(r, "GET", "/plain")
+	assert.NotContains(t, buffer.String(), "content_encoding")
+	assert.NotContains(t, buffer.String(), "compressed_size")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/etag", header{"If-None-Match", `"v1"`})
+	assert.Contains(t, buffer.String(), "304")
+	assert.Contains(t, buffer.String(), "etag_match=true")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/etag", header{"If-None-Match", `"v0"`})
+	assert.NotContains(t, buffer.String(), "etag_match")
+}
+
+func TestEtagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"a", "b"`, `"b"`))
+	assert.True(t, etagMatches(`W/"a"`, `"a"`))
+	assert.True(t, etagMatches(`*`, `"a"`))
+	assert.False(t, etagMatches(`"a"`, `"b"`))
+}
+
+func TestLoggerRangeFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithRangeFields(true)))
+	content := strings.NewReader("0123456789")
+	r.GET("/file", func(c *gin.Context) {
+		http.ServeContent(c.Writer, c.Request, "file.txt", time.Time{}, content)
+	})
+
+	performRequest(r, "GET", "/file", header{"Range", "bytes=2-5"})
+	assert.Contains(t, buffer.String(), "206")
+	assert.Contains(t, buffer.String(), "range=bytes=2-5")
+	assert.Contains(t, buffer.String(), `content_range="bytes 2-5/10"`)
+	assert.Contains(t, buffer.String(), "bytes_served=4")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/file")
+	assert.NotContains(t, buffer.String(), "bytes_served")
+}
+
+func TestLoggerTrailers(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithTrailers("Grpc-Status", "Checksum")))
+	r.POST("/declared", func(c *gin.Context) {
+		c.Header("Trailer", "Grpc-Status")
+		c.String(http.StatusOK, "ok")
+		c.Header("Grpc-Status", "5")
+	})
+	r.POST("/prefixed", func(c *gin.Context) {
+		_, _ = io.Copy(io.Discard, c.Request.Body)
+		c.String(http.StatusOK, "ok")
+		c.Header(http.TrailerPrefix+"Grpc-Status", "0")
+	})
+
+	performRequest(r, "POST", "/declared")
+	assert.Contains(t, buffer.String(), `response_trailers={"Grpc-Status":"5"}`)
+	assert.NotContains(t, buffer.String(), "request_trailers")
+
+	buffer.Reset()
+	req := httptest.NewRequest("POST", "/prefixed", strings.NewReader("body"))
+	req.Trailer = http.Header{"Checksum": []string{"abc"}}
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Contains(t, buffer.String(), `request_trailers={"Checksum":"abc"}`)
+	assert.Contains(t, buffer.String(), `response_trailers={"Grpc-Status":"0"}`)
+}
+
+func TestLoggerAuthUser(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithAuthUser(true), WithTrailers("Authorization"), WithRedactors()))
+	r.GET("/example", func(c *gin.Context) {
+		c.Header(http.TrailerPrefix+"Authorization", c.GetHeader("Authorization"))
+	})
+
+	credentials := base64.StdEncoding.EncodeToString([]byte("jane:s3cret"))
+	performRequest(r, "GET", "/example", header{"Authorization", "Basic " + credentials})
+	assert.Contains(t, buffer.String(), "auth_user=jane")
+	assert.Contains(t, buffer.String(), `"Authorization":"Basic [REDACTED]"`)
+	assert.NotContains(t, buffer.String(), "s3cret")
+	assert.NotContains(t, buffer.String(), credentials)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example", header{"Authorization", "Bearer abc"})
+	assert.NotContains(t, buffer.String(), "auth_user")
+	assert.NotContains(t, buffer.String(), "abc")
+}
+
+func TestLoggerSession(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/plain", SetLogger(WithWriter(buffer), WithSessionCookie("sid", false)), func(c *gin.Context) {})
+	r.GET("/hashed", SetLogger(WithWriter(buffer), WithSessionCookie("sid", true)), func(c *gin.Context) {})
+	r.GET("/custom", SetLogger(WithWriter(buffer), WithSession(func(c *gin.Context) string {
+		return c.GetHeader("X-Session")
+	})), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/plain", header{"Cookie", "sid=abc123"})
+	assert.Contains(t, buffer.String(), "session_id=abc123")
+
+	buffer.Reset()
+	sum := sha256.Sum256([]byte("abc123"))
+	performRequest(r, "GET", "/hashed", header{"Cookie", "sid=abc123"})
+	assert.Contains(t, buffer.String(), "session_id="+hex.EncodeToString(sum[:]))
+	assert.NotContains(t, buffer.String(), "abc123")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/hashed")
+	assert.NotContains(t, buffer.String(), "session_id")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/custom", header{"X-Session", "s-1"})
+	assert.Contains(t, buffer.String(), "session_id=s-1")
+}
+
+func TestLoggerConditionalFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithConditionalFields(true)))
+	r.GET("/doc", func(c *gin.Context) {
+		c.Header("ETag", `"v2"`)
+		if etagMatches(c.GetHeader("If-None-Match"), `"v2"`) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+		c.String(http.StatusOK, "doc")
+	})
+
+	performRequest(r, "GET", "/doc", header{"If-None-Match", `W/"v2"`})
+	assert.Contains(t, buffer.String(), `conditional={"etag_match":true,"if_modified_since":false,"if_none_match":true,"not_modified":true}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/doc", header{"If-Modified-Since", "Mon, 01 Jan 2024 00:00:00 GMT"})
+	assert.Contains(t, buffer.String(), `conditional={"if_modified_since":true,"if_none_match":false,"not_modified":false}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/doc")
+	assert.NotContains(t, buffer.String(), "conditional")
+}
+
+func TestLoggerEnvoyFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithEnvoyFields(true)))
+	r.GET("/example", func(c *gin.Context) {
+		c.Header("X-Envoy-Upstream-Service-Time", "12")
+	})
+
+	performRequest(r, "GET", "/example",
+		header{"X-Envoy-Attempt-Count", "2"},
+		header{"X-Envoy-External-Address", "203.0.113.5"},
+		header{"X-Envoy-Peer-Metadata", "Ci8KDkFQUF9DT05UQUlORVJT"})
+	assert.Contains(t, buffer.String(), `envoy={"attempt_count":2,"external_address":"203.0.113.5"}`)
+	assert.Contains(t, buffer.String(), `envoy_response={"upstream_service_time":12}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example", header{"X-Request-ID", "1"})
+	assert.NotContains(t, buffer.String(), "envoy=")
+	assert.Contains(t, buffer.String(), `envoy_response={"upstream_service_time":12}`)
+}
+
+func TestLoggerEnvoyFieldsBothSides(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithAutoFormat(), WithEnvoyFields(true)))
+	r.GET("/example", func(c *gin.Context) {
+		c.Header("X-Envoy-Decorator-Operation", "orders")
+		c.Header("X-Envoy-Upstream-Service-Time", "12")
+	})
+
+	// The same header on both sides is logged once in each group, and the
+	// fields follow the order of the header names, whatever the map order.
+	for i := 0; i < 10; i++ {
+		buffer.Reset()
+		performRequest(r, "GET", "/example",
+			header{"X-Envoy-Expected-Rq-Timeout-Ms", "1000"},
+			header{"X-Envoy-Decorator-Operation", "checkout"},
+			header{"X-Envoy-Attempt-Count", "1"})
+		assert.Contains(t, buffer.String(), `"envoy":{"attempt_count":1,"decorator_operation":"checkout","expected_rq_timeout_ms":1000}`)
+		assert.Contains(t, buffer.String(), `"envoy_response":{"decorator_operation":"orders","upstream_service_time":12}`)
+	}
+}
+
+func TestLoggerRetryFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithRetryFields("X-Attempt")))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example", header{"X-Envoy-Attempt-Count", "3"}, header{"X-Attempt", "1"})
+	assert.Contains(t, buffer.String(), "attempt=3 ")
+	assert.Contains(t, buffer.String(), "is_retry=true")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example", header{"X-Retry-Attempt", "oops"}, header{"X-Attempt", "1"})
+	assert.Contains(t, buffer.String(), "attempt=1 ")
+	assert.Contains(t, buffer.String(), "is_retry=false")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example")
+	assert.NotContains(t, buffer.String(), "attempt")
+}
+
+func TestLoggerQueueTime(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithClock(&stepClock{now: now}), WithQueueTime(true)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	tests := []struct {
+		name   string
+		header header
+		want   string
+	}{
+		{"nginx", header{"X-Request-Start", fmt.Sprintf("t=%d.250", now.Unix()-1)}, "queue_time=750"},
+		{"heroku", header{"X-Request-Start", strconv.FormatInt(now.UnixMilli()-40, 10)}, "queue_time=40"},
+		{"micro", header{"X-Queue-Start", "t=" + strconv.FormatInt(now.UnixMicro()-1500, 10)}, "queue_time=1.5"},
+		{"seconds", header{"X-Request-Start", strconv.FormatInt(now.Unix()-2, 10)}, "queue_time=2000"},
+		{"future", header{"X-Request-Start", strconv.FormatInt(now.UnixMilli()+40, 10)}, ""},
+		{"invalid", header{"X-Request-Start", "t=soon"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer.Reset()
+			performRequest(r, "GET", "/example", tt.header)
+			if tt.want == "" {
+				assert.NotContains(t, buffer.String(), "queue_time")
+				return
+			}
+			assert.Contains(t, buffer.String(), tt.want+" ")
+		})
+	}
+}
+
+func TestLoggerIdempotencyKey(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.POST("/plain", SetLogger(WithWriter(buffer), WithIdempotencyKey("Idempotency-Key", false)), func(c *gin.Context) {})
+	r.POST("/hashed", SetLogger(WithWriter(buffer), WithIdempotencyKey("Idempotency-Key", true)), func(c *gin.Context) {})
+
+	performRequest(r, "POST", "/plain", header{"Idempotency-Key", "k-42"})
+	assert.Contains(t, buffer.String(), "idempotency_key=k-42")
+
+	buffer.Reset()
+	sum := sha256.Sum256([]byte("k-42"))
+	performRequest(r, "POST", "/hashed", header{"Idempotency-Key", "k-42"})
+	assert.Contains(t, buffer.String(), "idempotency_key="+hex.EncodeToString(sum[:]))
+
+	buffer.Reset()
+	performRequest(r, "POST", "/plain")
+	assert.NotContains(t, buffer.String(), "idempotency_key")
+}
+
+func TestLoggerRateLimitFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithRateLimitFields(true)))
+	r.GET("/ok", func(c *gin.Context) {
+		c.Header("X-RateLimit-Limit", "100")
+		c.Header("X-RateLimit-Remaining", "99")
+		c.Header("RateLimit-Reset", "30")
+	})
+	r.GET("/limited", func(c *gin.Context) {
+		c.Header("Retry-After", "10")
+		c.AbortWithStatus(http.StatusTooManyRequests)
+	})
+	r.GET("/bare", func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusTooManyRequests)
+	})
+	r.GET("/none", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/ok")
+	assert.Contains(t, buffer.String(), `ratelimit={"limit":"100","limited":false,"remaining":"99","reset":"30"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/limited")
+	assert.Contains(t, buffer.String(), `ratelimit={"limited":true,"retry_after":"10"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/bare")
+	assert.Contains(t, buffer.String(), `ratelimit={"limited":true}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/none")
+	assert.NotContains(t, buffer.String(), "ratelimit")
+}
+
+func TestLoggerCORSFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithCORSFields(true)))
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("Origin") == "https://good.example" {
+			c.Header("Access-Control-Allow-Origin", "https://good.example")
+		}
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+		}
+	})
+	r.GET("/example", func(c *gin.Context) {})
+	r.OPTIONS("/example", func(c *gin.Context) {})
+
+	performRequest(r, "OPTIONS", "/example",
+		header{"Origin", "https://good.example"}, header{"Access-Control-Request-Method", "GET"})
+	assert.Contains(t, buffer.String(), `cors={"allowed":true,"origin":"https://good.example","preflight":true}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example", header{"Origin", "https://evil.example"})
+	assert.Contains(t, buffer.String(), `cors={"allowed":false,"origin":"https://evil.example","preflight":false}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example")
+	assert.NotContains(t, buffer.String(), "cors")
+}
+
+func TestLoggerNegotiationFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithNegotiationFields(true)))
+	r.GET("/example", func(c *gin.Context) {
+		c.Header("Content-Language", "de")
+	})
+	r.GET("/none", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example", header{"Accept-Language", "de-CH, de;q=0.9"}, header{"Accept-Encoding", "br"})
+	assert.Contains(t, buffer.String(),
+		`negotiation={"accept_encoding":"br","accept_language":"de-CH, de;q=0.9","content_language":"de"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/none")
+	assert.NotContains(t, buffer.String(), "negotiation")
+}
+
+func TestLoggerTrafficClass(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithTrafficClass(func(c *gin.Context) string {
+		if c.GetHeader("X-Batch") != "" {
+			return "batch"
+		}
+		return "interactive"
+	})))
+	r.GET("/example", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler")
+	})
+
+	performRequest(r, "GET", "/example", header{"X-Batch", "1"})
+	assert.Equal(t, 2, strings.Count(buffer.String(), "traffic_class=batch"))
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example")
+	assert.Equal(t, 2, strings.Count(buffer.String(), "traffic_class=interactive"))
+}
+
+func TestLoggerRouteParams(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/all/:org/:id", SetLogger(WithWriter(buffer), WithRouteParams(true)), func(c *gin.Context) {})
+	r.GET("/some/:org/:id", SetLogger(WithWriter(buffer), WithRouteParams(true, "id")), func(c *gin.Context) {})
+	r.GET("/none", SetLogger(WithWriter(buffer), WithRouteParams(true)), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/all/acme/42")
+	assert.Contains(t, buffer.String(), `params={"id":"42","org":"acme"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/some/acme/42")
+	assert.Contains(t, buffer.String(), `params={"id":"42"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/none")
+	assert.NotContains(t, buffer.String(), "params")
+}
+
+func TestLatencyBuckets(t *testing.T) {
+	b := newLatencyBuckets([]time.Duration{time.Second, 10 * time.Millisecond, 100 * time.Millisecond})
+	assert.Equal(t, "<10ms", b.bucket(time.Millisecond))
+	assert.Equal(t, "10ms-100ms", b.bucket(10*time.Millisecond))
+	assert.Equal(t, "100ms-1s", b.bucket(500*time.Millisecond))
+	assert.Equal(t, ">1s", b.bucket(2*time.Second))
+
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithLatencyBuckets()))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "latency_bucket=<10ms")
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// FlagsKey is the context key under which the feature flags evaluated for a
+// request are recorded, as a map[string]any of flag name to variant. Feature flag
+// clients can write it directly or through RecordFlag.
+const FlagsKey = "_gin-contrib/logger_flags_"
+
+// RecordFlag records the variant of a feature flag evaluated for the request, so
+// WithFlags logs it on the access line.
+func RecordFlag(c *gin.Context, name string, variant any) {
+	flags := recordedFlags(c)
+	if flags == nil {
+		flags = make(map[string]any)
+		c.Set(FlagsKey, flags)
+	}
+	flags[name] = variant
+}
+
+// recordedFlags returns the flags recorded under FlagsKey.
+func recordedFlags(c *gin.Context) map[string]any {
+	v, ok := c.Get(FlagsKey)
+	if !ok {
+		return nil
+	}
+	flags, _ := v.(map[string]any)
+	return flags
+}
+
+// FlagsHook is called after the handler chain with the feature flags recorded
+// for the request, e.g. to report exposures to an experimentation service. It
+// returns the flags to log, so it can also filter or rename them.
+type FlagsHook interface {
+	Exposed(c *gin.Context, flags map[string]any) map[string]any
+}
+
+// FlagsHookFunc is an adapter to use an ordinary function as a FlagsHook.
+type FlagsHookFunc func(c *gin.Context, flags map[string]any) map[string]any
+
+// Exposed calls f(c, flags).
+func (f FlagsHookFunc) Exposed(c *gin.Context, flags map[string]any) map[string]any {
+	return f(c, flags)
+}
+
+// flagsFields returns a field extractor logging the recorded flags as a "flags" group.
+func flagsFields(hook FlagsHook) EventFn {
+	return func(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+		flags := recordedFlags(c)
+		if len(flags) == 0 {
+			return evt
+		}
+		if hook != nil {
+			flags = hook.Exposed(c, flags)
+		}
+		if len(flags) == 0 {
+			return evt
+		}
+		return evt.Dict("flags", zerolog.Dict().Fields(flags))
+	}
+}
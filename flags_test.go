@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerFlags(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	var exposed map[string]any
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/plain", SetLogger(WithWriter(buffer), WithFlags(nil)), func(c *gin.Context) {
+		RecordFlag(c, "new-checkout", "treatment")
+		RecordFlag(c, "dark-mode", true)
+	})
+	r.GET("/hooked", SetLogger(WithWriter(buffer), WithFlags(FlagsHookFunc(func(_ *gin.Context, flags map[string]any) map[string]any {
+		exposed = flags
+		return map[string]any{"new-checkout": flags["new-checkout"]}
+	}))), func(c *gin.Context) {
+		c.Set(FlagsKey, map[string]any{"new-checkout": "control", "internal": 1})
+	})
+	r.GET("/none", SetLogger(WithWriter(buffer), WithFlags(nil)), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/plain")
+	assert.Contains(t, buffer.String(), `flags={"dark-mode":true,"new-checkout":"treatment"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/hooked")
+	assert.Contains(t, buffer.String(), `flags={"new-checkout":"control"}`)
+	assert.Equal(t, map[string]any{"new-checkout": "control", "internal": 1}, exposed)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/none")
+	assert.NotContains(t, buffer.String(), "flags")
+}
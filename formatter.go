@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LogParams holds the values of a log event passed to a Formatter. Standard
+// fields of the access line are decoded into their own members; every other
+// field, including those added by options and WithContext, is kept in Fields.
+// Events written by handlers through Get(c) have zero values for the members
+// they do not set.
+type LogParams struct {
+	Time      time.Time
+	Level     zerolog.Level
+	Message   string
+	Status    int
+	Method    string
+	Path      string
+	Route     string
+	ClientIP  string
+	UserAgent string
+	Latency   time.Duration
+	BodySize  int
+	// Errors holds the messages of the errors recorded with Error and Errorf.
+	Errors []string
+	// Fields holds the remaining fields of the event.
+	Fields map[string]any
+}
+
+// Formatter renders a log event. The returned bytes are written as they are,
+// so they should end with a newline. Empty output drops the event.
+type Formatter func(params LogParams) ([]byte, error)
+
+// formatWriter is the writer used when WithFormatter is set.
+type formatWriter struct {
+	out io.Writer
+	fn  Formatter
+}
+
+// Write decodes the JSON event p and writes the output of the formatter.
+func (w formatWriter) Write(p []byte) (int, error) {
+	params, err := decodeParams(p)
+	if err != nil {
+		return 0, err
+	}
+	b, err := w.fn(params)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) == 0 {
+		return len(p), nil
+	}
+	if _, err := w.out.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// decodeParams decodes the JSON event p into LogParams.
+func decodeParams(p []byte) (LogParams, error) {
+	fields, ok := scanFields(p)
+	if !ok {
+		fields = map[string]any{}
+		d := json.NewDecoder(bytes.NewReader(p))
+		d.UseNumber()
+		if err := d.Decode(&fields); err != nil {
+			return LogParams{}, err
+		}
+	}
+
+	params := LogParams{Level: zerolog.NoLevel, Fields: fields}
+	str := func(key string) string {
+		s, _ := fields[key].(string)
+		delete(fields, key)
+		return s
+	}
+	if t, err := time.Parse(zerolog.TimeFieldFormat, str(zerolog.TimestampFieldName)); err == nil {
+		params.Time = t
+	}
+	if l, err := zerolog.ParseLevel(str(zerolog.LevelFieldName)); err == nil {
+		params.Level = l
+	}
+	params.Message = str(zerolog.MessageFieldName)
+	params.Method = str("method")
+	params.Path = str("path")
+	params.Route = str("route")
+	params.ClientIP = str("ip")
+	params.UserAgent = str("user_agent")
+	if n, ok := number(fields["status"]); ok {
+		params.Status = int(n)
+		delete(fields, "status")
+	}
+	if n, ok := number(fields["body_size"]); ok {
+		params.BodySize = int(n)
+		delete(fields, "body_size")
+	}
+	if n, ok := number(fields["latency"]); ok {
+		params.Latency = time.Duration(n * float64(zerolog.DurationFieldUnit))
+		delete(fields, "latency")
+	}
+	if errs, ok := fields["errors"].([]any); ok {
+		for _, e := range errs {
+			if e, ok := e.(map[string]any); ok {
+				if msg, ok := e["error"].(string); ok {
+					params.Errors = append(params.Errors, msg)
+				}
+			}
+		}
+		delete(fields, "errors")
+	}
+	return params, nil
+}
+
+// internedKey reports whether the values of the field key are interned when
+// decoded, on top of those interned when the event was written.
+func internedKey(key []byte) bool {
+	return string(key) == zerolog.LevelFieldName || string(key) == zerolog.MessageFieldName
+}
+
+// scanFields decodes the flat JSON event p as a json.Decoder using numbers
+// would, without most of its allocations: keys, and string values interned
+// when the event was written, such as the method and route, reuse the cached
+// strings. It reports
+// false for events scanEvent does not handle, such as events with errors.
+func scanFields(p []byte) (map[string]any, bool) {
+	st := consolePool.Get().(*consoleState)
+	defer consolePool.Put(st)
+
+	var ok bool
+	if st.fields, ok = scanEvent(p, st.fields[:0]); !ok {
+		return nil, false
+	}
+	fields := make(map[string]any, len(st.fields))
+	for _, f := range st.fields {
+		var v any
+		switch {
+		case f.str:
+			v = interned.value(f.value, internedKey(f.key))
+		case string(f.value) == "true":
+			v = true
+		case string(f.value) == "false":
+			v = false
+		case string(f.value) == "null":
+			v = nil
+		default:
+			v = json.Number(f.value)
+		}
+		fields[interned.string(f.key, true)] = v
+	}
+	return fields, true
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerFormatter(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	var got []LogParams
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithContext(func(_ *gin.Context, e *zerolog.Event) *zerolog.Event {
+			return e.Str("tenant", "acme")
+		}),
+		WithFormatter(func(p LogParams) ([]byte, error) {
+			got = append(got, p)
+			return []byte(fmt.Sprintf("%s %s %d %s\n", p.Method, p.Route, p.Status, strings.Join(p.Errors, ","))), nil
+		}),
+	))
+	r.GET("/users/:id", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler")
+		Error(c, errors.New("boom"))
+		c.String(500, "oops")
+	})
+
+	performRequest(r, "GET", "/users/42")
+	assert.Equal(t, "GET  0 \nGET /users/:id 500 boom\n", buffer.String())
+	assert.Len(t, got, 2)
+
+	p := got[1]
+	assert.Equal(t, zerolog.ErrorLevel, p.Level)
+	assert.Equal(t, "Request", p.Message)
+	assert.Equal(t, "/users/42", p.Path)
+	assert.Equal(t, 4, p.BodySize)
+	assert.Equal(t, "acme", p.Fields["tenant"])
+	assert.NotContains(t, p.Fields, "status")
+	assert.False(t, p.Time.IsZero())
+	assert.Less(t, p.Latency, time.Second)
+	assert.Equal(t, "handler", got[0].Message)
+}
+
+func TestFormatWriterErrors(t *testing.T) {
+	w := formatWriter{out: new(bytes.Buffer), fn: func(LogParams) ([]byte, error) {
+		return nil, errors.New("format")
+	}}
+	_, err := w.Write([]byte("not json"))
+	assert.Error(t, err)
+	_, err = w.Write([]byte(`{"level":"info"}`))
+	assert.EqualError(t, err, "format")
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinFormatter returns a Formatter replicating the default output of gin.Logger(),
+// with ANSI colors when color is true, so the middleware can replace gin.Logger()
+// without breaking existing log parsing. Events written by handlers through Get(c)
+// are dropped, since gin.Logger() writes nothing but access lines. The errors
+// printed below an access line are its message: WithGinFormat restricts it to
+// the private errors of the request, as gin.Logger() does.
+func GinFormatter(color bool) Formatter {
+	return func(p LogParams) ([]byte, error) {
+		if p.Status == 0 {
+			return nil, nil
+		}
+
+		param := gin.LogFormatterParams{
+			StatusCode: p.Status,
+			Method:     p.Method,
+		}
+		var statusColor, methodColor, resetColor string
+		if color {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+
+		var errorMessage string
+		if p.Message != "Request" {
+			errorMessage = p.Message
+		}
+
+		latency := p.Latency
+		if latency > time.Minute {
+			latency = latency.Truncate(time.Second)
+		}
+		return []byte(fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			p.Time.Format("2006/01/02 - 15:04:05"),
+			statusColor, p.Status, resetColor,
+			latency,
+			p.ClientIP,
+			methodColor, p.Method, resetColor,
+			p.Path,
+			errorMessage,
+		)), nil
+	}
+}
+
+// ginColor reports whether gin.Logger() would color its output to w.
+func ginColor(w any) bool {
+	if (&gin.LogFormatterParams{}).IsOutputColor() {
+		return true
+	}
+	f, ok := w.(*os.File)
+	return ok && os.Getenv("TERM") != "dumb" && isTerminal(f)
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGinFormatter(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 20, 30, 0, time.UTC)
+	p := LogParams{
+		Time:     ts,
+		Status:   404,
+		Method:   "GET",
+		Path:     "/missing?x=1",
+		ClientIP: "10.0.0.1",
+		Latency:  1500 * time.Microsecond,
+		Message:  "Request",
+	}
+
+	b, err := GinFormatter(false)(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "[GIN] 2024/05/01 - 10:20:30 | 404 |         1.5ms |        10.0.0.1 | GET      \"/missing?x=1\"\n", string(b))
+
+	b, err = GinFormatter(true)(p)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "|\x1b[90;43m 404 \x1b[0m|")
+	assert.Contains(t, string(b), "|\x1b[97;44m GET     \x1b[0m ")
+
+	p.Message = "Error #01: boom\n"
+	p.Latency = 90*time.Second + time.Millisecond
+	b, err = GinFormatter(false)(p)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), "|         1m30s |")
+	assert.Contains(t, string(b), "\"/missing?x=1\"\nError #01: boom\n")
+
+	b, err = GinFormatter(false)(LogParams{Message: "handler"})
+	assert.NoError(t, err)
+	assert.Empty(t, b)
+}
+
+func TestLoggerGinFormat(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithGinFormat()))
+	r.GET("/fail", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler event")
+		_ = c.Error(errors.New("boom"))
+		_ = c.Error(errors.New("shown to the client")).SetType(gin.ErrorTypePublic)
+		c.Status(500)
+	})
+
+	performRequest(r, "GET", "/fail")
+	assert.Regexp(t, `^\[GIN\] \d{4}/\d\d/\d\d - \d\d:\d\d:\d\d \| 500 \| +\S+ \| +\S* \| GET +"/fail"\nError #01: boom\n$`, buffer.String())
+}
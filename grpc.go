@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// grpcStatusHeader is the header or trailer carrying the gRPC status code.
+const grpcStatusHeader = "Grpc-Status"
+
+// grpcCodes are the names of the gRPC status codes, indexed by code.
+var grpcCodes = [...]string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+// grpcStatus returns the gRPC status code of the response, read from the
+// grpc-status trailer, or from the header for trailers-only responses.
+func grpcStatus(c *gin.Context) (int, bool) {
+	h := c.Writer.Header()
+	v := h.Get(http.TrailerPrefix + grpcStatusHeader)
+	if v == "" {
+		v = h.Get(grpcStatusHeader)
+	}
+	if v == "" {
+		return 0, false
+	}
+	code, err := strconv.Atoi(v)
+	return code, err == nil && code >= 0
+}
+
+// grpcCodeName returns the name of a gRPC status code.
+func grpcCodeName(code int) string {
+	if code < len(grpcCodes) {
+		return grpcCodes[code]
+	}
+	return strconv.Itoa(code)
+}
+
+// grpcLevel returns the level of a gRPC status code: codes caused by the caller
+// use the client error level, other failures the server error level.
+func grpcLevel(code int, clientErrorLevel, serverErrorLevel zerolog.Level) (zerolog.Level, bool) {
+	switch grpcCodeName(code) {
+	case "OK":
+		return zerolog.NoLevel, false
+	case "CANCELLED", "INVALID_ARGUMENT", "NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED",
+		"FAILED_PRECONDITION", "OUT_OF_RANGE", "UNAUTHENTICATED":
+		return clientErrorLevel, true
+	default:
+		return serverErrorLevel, true
+	}
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerGRPCStatus(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithGRPCStatus()))
+	r.POST("/ok", func(c *gin.Context) {
+		c.Header("Trailer", grpcStatusHeader)
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("payload")
+		c.Header(http.TrailerPrefix+grpcStatusHeader, "0")
+	})
+	r.POST("/unavailable", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("payload")
+		c.Header(http.TrailerPrefix+grpcStatusHeader, "14")
+	})
+	r.POST("/not-found", func(c *gin.Context) {
+		c.Header(grpcStatusHeader, "5")
+		c.Status(http.StatusOK)
+	})
+	r.POST("/plain", func(c *gin.Context) {})
+
+	performRequest(r, "POST", "/ok")
+	assert.Contains(t, buffer.String(), "INF")
+	assert.Contains(t, buffer.String(), "grpc_code=OK")
+
+	buffer.Reset()
+	performRequest(r, "POST", "/unavailable")
+	assert.Contains(t, buffer.String(), "ERR")
+	assert.Contains(t, buffer.String(), "grpc_code=UNAVAILABLE")
+	assert.Contains(t, buffer.String(), "status=200")
+
+	buffer.Reset()
+	performRequest(r, "POST", "/not-found")
+	assert.Contains(t, buffer.String(), "WRN")
+	assert.Contains(t, buffer.String(), "grpc_code=NOT_FOUND")
+
+	buffer.Reset()
+	performRequest(r, "POST", "/plain")
+	assert.NotContains(t, buffer.String(), "grpc_code")
+}
+
+func TestGRPCCodeName(t *testing.T) {
+	assert.Equal(t, "UNAUTHENTICATED", grpcCodeName(16))
+	assert.Equal(t, "42", grpcCodeName(42))
+}
@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthStatus reports the state of the logging pipelines of the process: the
+// writers of each open Pipeline and of the last middleware built by SetLogger,
+// New or ForGroup under each name, and the writers created with NewBatchWriter
+// or NewAsyncWriter outside of a middleware.
+type HealthStatus struct {
+	// WriterOK is false when the last write to the output of any pipeline failed.
+	WriterOK bool `json:"writer_ok"`
+	// QueueDepth is the number of events waiting in asynchronous writers.
+	QueueDepth int `json:"queue_depth"`
+	// DroppedEvents is the number of events that could not be written.
+	DroppedEvents int64 `json:"dropped_events"`
+	// LastWriteError is the last error returned by a log output, if any.
+	LastWriteError string `json:"last_write_error,omitempty"`
+	// LastWriteErrorAt is when LastWriteError occurred.
+	LastWriteErrorAt time.Time `json:"last_write_error_at,omitempty"`
+	// Levels lists the distinct level configurations of the SetLogger instances.
+	Levels []Levels `json:"levels"`
+	// Backpressure aggregates the counters of asynchronous writers by policy.
+	Backpressure map[string]AsyncStats `json:"backpressure,omitempty"`
+	// Pipelines reports each pipeline apart, so a broken one is not hidden by
+	// the others. Writers created outside of a middleware are reported as the
+	// "standalone" pipeline once they wrote or dropped events.
+	Pipelines []PipelineHealth `json:"pipelines"`
+}
+
+// PipelineHealth reports the state of the writers of a single pipeline, named
+// with WithName.
+type PipelineHealth struct {
+	Name             string    `json:"name"`
+	WriterOK         bool      `json:"writer_ok"`
+	QueueDepth       int       `json:"queue_depth"`
+	DroppedEvents    int64     `json:"dropped_events"`
+	LastWriteError   string    `json:"last_write_error,omitempty"`
+	LastWriteErrorAt time.Time `json:"last_write_error_at,omitempty"`
+	// Levels is the level configuration of the middleware, unset for the
+	// standalone pipeline.
+	Levels *Levels `json:"levels,omitempty"`
+}
+
+// Levels is the level configuration of a SetLogger instance.
+type Levels struct {
+	Default     string `json:"default"`
+	ClientError string `json:"client_error"`
+	ServerError string `json:"server_error"`
+}
+
+// pipelineHealth tracks the state of the writers of a pipeline.
+type pipelineHealth struct {
+	name         string
+	writeFailing atomic.Bool
+	written      atomic.Bool
+	dropped      *shardedCounter
+
+	mu          sync.Mutex
+	lastErr     error
+	lastErrAt   time.Time
+	levels      func() Levels
+	queueDepths map[flusher]func() int
+	async       map[*AsyncWriter]struct{}
+}
+
+func newPipelineHealth(name string) *pipelineHealth {
+	return &pipelineHealth{
+		name:        name,
+		dropped:     newShardedCounter(),
+		queueDepths: make(map[flusher]func() int),
+		async:       make(map[*AsyncWriter]struct{}),
+	}
+}
+
+// health tracks the writers created outside of a middleware.
+var health = newPipelineHealth("standalone")
+
+var (
+	healthsMu sync.Mutex
+	healths   []*pipelineHealth
+	healthSeq int
+)
+
+// defaultPipelineName is the name of the middleware built by SetLogger, New
+// and ForGroup without WithName.
+const defaultPipelineName = "default"
+
+// nextPipelineName returns the name of a Pipeline created without WithName,
+// numbered after the pipelines created before it.
+func nextPipelineName() string {
+	healthsMu.Lock()
+	defer healthsMu.Unlock()
+	healthSeq++
+	return "pipeline-" + strconv.Itoa(healthSeq)
+}
+
+// registerHealth returns the health of a new pipeline named name, reported by
+// Health until the returned function is called. It replaces the pipeline
+// registered under the same name, if any, so rebuilding a middleware does not
+// add an entry to the report.
+func registerHealth(name string) (*pipelineHealth, func()) {
+	healthsMu.Lock()
+	defer healthsMu.Unlock()
+	h := newPipelineHealth(name)
+	replaced := false
+	for i, q := range healths {
+		if q.name == name {
+			healths[i], replaced = h, true
+			break
+		}
+	}
+	if !replaced {
+		healths = append(healths, h)
+	}
+	return h, func() {
+		healthsMu.Lock()
+		defer healthsMu.Unlock()
+		for i, q := range healths {
+			if q == h {
+				healths = append(healths[:i], healths[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Health returns the current state of the logging pipelines, so they can be
+// monitored and alerted on like any other dependency.
+func Health() HealthStatus {
+	healthsMu.Lock()
+	hs := append([]*pipelineHealth{health}, healths...)
+	healthsMu.Unlock()
+
+	status := HealthStatus{WriterOK: true, Levels: []Levels{}}
+	levels := make(map[Levels]struct{})
+	for i, h := range hs {
+		ph := h.status()
+		if i == 0 && ph.Levels == nil && ph.LastWriteError == "" && ph.DroppedEvents == 0 && !h.written.Load() {
+			// The standalone pipeline is only reported once used.
+			continue
+		}
+		status.Pipelines = append(status.Pipelines, ph)
+		status.WriterOK = status.WriterOK && ph.WriterOK
+		status.QueueDepth += ph.QueueDepth
+		status.DroppedEvents += ph.DroppedEvents
+		if ph.LastWriteError != "" && ph.LastWriteErrorAt.After(status.LastWriteErrorAt) {
+			status.LastWriteError, status.LastWriteErrorAt = ph.LastWriteError, ph.LastWriteErrorAt
+		}
+		if ph.Levels != nil {
+			if _, ok := levels[*ph.Levels]; !ok {
+				levels[*ph.Levels] = struct{}{}
+				status.Levels = append(status.Levels, *ph.Levels)
+			}
+		}
+		h.addBackpressure(&status)
+	}
+	return status
+}
+
+// status returns the state of the pipeline.
+func (h *pipelineHealth) status() PipelineHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ph := PipelineHealth{
+		Name:          h.name,
+		WriterOK:      !h.writeFailing.Load(),
+		DroppedEvents: h.dropped.Load(),
+	}
+	if h.levels != nil {
+		levels := h.levels()
+		ph.Levels = &levels
+	}
+	if h.lastErr != nil {
+		ph.LastWriteError = h.lastErr.Error()
+		ph.LastWriteErrorAt = h.lastErrAt
+	}
+	for _, depth := range h.queueDepths {
+		ph.QueueDepth += depth()
+	}
+	return ph
+}
+
+// addBackpressure adds the counters of the asynchronous writers of the
+// pipeline to status.
+func (h *pipelineHealth) addBackpressure(status *HealthStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for a := range h.async {
+		if status.Backpressure == nil {
+			status.Backpressure = make(map[string]AsyncStats)
+		}
+		stats, policy := a.Stats(), a.policy.String()
+		total := status.Backpressure[policy]
+		total.Enqueued += stats.Enqueued
+		total.Dropped += stats.Dropped
+		total.Sampled += stats.Sampled
+		total.Blocked += stats.Blocked
+		status.Backpressure[policy] = total
+	}
+}
+
+// HealthHandler returns a gin.HandlerFunc responding with the Health of the
+// logging pipelines as JSON, with status 503 when the last write of any of them
+// failed.
+func HealthHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status := Health()
+		code := http.StatusOK
+		if !status.WriterOK {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, status)
+	}
+}
+
+// registerLevels records the level configuration of a SetLogger instance,
+// reported as changed by its Controller.
+func (h *pipelineHealth) registerLevels(cfg *config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.levels = func() Levels {
+		defaultLevel, clientErrorLevel, serverErrorLevel := cfg.defaultLevel, cfg.clientErrorLevel, cfg.serverErrorLevel
+		if snap := cfg.controller.load(); snap != nil && snap.hasLevels {
+			defaultLevel, clientErrorLevel, serverErrorLevel = snap.defaultLevel, snap.clientErrorLevel, snap.serverErrorLevel
+		}
+		return Levels{
+			Default:     defaultLevel.String(),
+			ClientError: clientErrorLevel.String(),
+			ServerError: serverErrorLevel.String(),
+		}
+	}
+}
+
+// registerQueue adds the queue depth of the writer w to the report.
+func (h *pipelineHealth) registerQueue(w flusher, depth func() int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.queueDepths[w] = depth
+}
+
+// registerAsync adds the counters of an asynchronous writer to the report.
+func (h *pipelineHealth) registerAsync(a *AsyncWriter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.async[a] = struct{}{}
+}
+
+// recordDrop counts an event that was discarded before reaching a log output.
+func (h *pipelineHealth) recordDrop() {
+	h.dropped.Add(1)
+}
+
+// recordWrite tracks the outcome of a write of events to a log output.
+func (h *pipelineHealth) recordWrite(err error, events int) {
+	if !h.written.Load() {
+		h.written.Store(true)
+	}
+	if err == nil {
+		if h.writeFailing.Load() {
+			h.writeFailing.Store(false)
+		}
+		return
+	}
+	h.writeFailing.Store(true)
+	h.dropped.Add(int64(events))
+	h.mu.Lock()
+	h.lastErr = err
+	h.lastErrAt = time.Now()
+	h.mu.Unlock()
+}
+
+// healthReporter is implemented by the writers reporting their writes and
+// drops to a health themselves, such as the BatchWriter returned by
+// NewClickHouseWriter. A pipeline writing to one makes it report to its own
+// health instead of the standalone one.
+type healthReporter interface {
+	reportTo(h *pipelineHealth)
+}
+
+// healthWriter reports the outcome of every write to the pipeline health.
+type healthWriter struct {
+	w io.Writer
+	h *pipelineHealth
+}
+
+func (hw healthWriter) Write(p []byte) (int, error) {
+	n, err := hw.w.Write(p)
+	hw.h.recordWrite(err, 1)
+	return n, err
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// pipelineHealthOf returns the health of the pipeline named name in status.
+func pipelineHealthOf(status HealthStatus, name string) (PipelineHealth, bool) {
+	for _, ph := range status.Pipelines {
+		if ph.Name == name {
+			return ph, true
+		}
+	}
+	return PipelineHealth{}, false
+}
+
+func TestHealth(t *testing.T) {
+	ok, err := NewPipeline(WithName("ok"), WithWriter(new(bytes.Buffer)), WithDefaultLevel(zerolog.DebugLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ok.Close()
+	fail, err := NewPipeline(WithName("fail"), WithWriter(failingWriter{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/ok", ok.Handler(), func(c *gin.Context) {})
+	r.GET("/fail", fail.Handler(), func(c *gin.Context) {})
+	r.GET("/health", HealthHandler())
+
+	dropped := Health().DroppedEvents
+	performRequest(r, "GET", "/fail")
+	status := Health()
+	assert.False(t, status.WriterOK)
+	assert.Equal(t, dropped+1, status.DroppedEvents)
+	assert.Equal(t, "disk full", status.LastWriteError)
+	assert.False(t, status.LastWriteErrorAt.IsZero())
+	assert.Contains(t, status.Levels, Levels{Default: "debug", ClientError: "warn", ServerError: "error"})
+
+	resp := performRequest(r, "GET", "/health")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+
+	// A working pipeline does not hide the broken one.
+	performRequest(r, "GET", "/ok")
+	status = Health()
+	assert.False(t, status.WriterOK)
+	if ph, found := pipelineHealthOf(status, "ok"); assert.True(t, found) {
+		assert.True(t, ph.WriterOK)
+		assert.Equal(t, "debug", ph.Levels.Default)
+	}
+	if ph, found := pipelineHealthOf(status, "fail"); assert.True(t, found) {
+		assert.False(t, ph.WriterOK)
+		assert.Equal(t, int64(1), ph.DroppedEvents)
+		assert.Equal(t, "disk full", ph.LastWriteError)
+	}
+	assert.False(t, fail.Health().WriterOK)
+
+	resp = performRequest(r, "GET", "/health")
+	var body HealthStatus
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	_, found := pipelineHealthOf(body, "fail")
+	assert.True(t, found)
+
+	assert.NoError(t, fail.Close())
+	_, found = pipelineHealthOf(Health(), "fail")
+	assert.False(t, found)
+}
+
+func TestHealthRebuiltMiddleware(t *testing.T) {
+	ctl := NewController()
+	for i := 0; i < 3; i++ {
+		SetLogger(WithName("rebuilt"), WithWriter(new(bytes.Buffer)), WithController(ctl))
+	}
+	count := 0
+	for _, ph := range Health().Pipelines {
+		if ph.Name == "rebuilt" {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+
+	ctl.SetLevels(zerolog.DebugLevel, zerolog.InfoLevel, zerolog.WarnLevel)
+	if ph, found := pipelineHealthOf(Health(), "rebuilt"); assert.True(t, found) {
+		assert.Equal(t, Levels{Default: "debug", ClientError: "info", ServerError: "warn"}, *ph.Levels)
+	}
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// internLimit bounds the number of values cached by an interner, so values
+// chosen by clients, such as user agents, cannot grow it without limit. Once
+// it is full, other values are encoded for each event as usual.
+const internLimit = 1024
+
+// internedValue is a cached field value, as a string, boxed in an interface,
+// and JSON encoded.
+type internedValue struct {
+	s    string
+	v    any
+	json []byte
+}
+
+// interner caches frequently repeated field values, such as methods, route
+// templates and common user agents, along with their JSON encoding, so events
+// reuse the cached bytes instead of escaping the value again, and decoded
+// events reuse the cached string instead of allocating it.
+//
+// The cache is an immutable map published through an atomic pointer, as the
+// Controller publishes its settings: lookups take no lock, and a value is
+// added by copying the map, which stops once it holds internLimit values.
+type interner struct {
+	mu     sync.Mutex
+	values atomic.Pointer[map[string]internedValue]
+}
+
+// interned caches the values of the access events of every middleware.
+var interned = &interner{}
+
+// lookup returns the cached value equal to b. The conversion of b to a map key
+// does not allocate.
+func (in *interner) lookup(b []byte) (internedValue, bool) {
+	if m := in.values.Load(); m != nil {
+		v, ok := (*m)[string(b)]
+		return v, ok
+	}
+	return internedValue{}, false
+}
+
+// intern returns the cached value equal to s, caching s when there is room and
+// it can be encoded without escaping.
+func (in *interner) intern(s string) (internedValue, bool) {
+	if m := in.values.Load(); m != nil {
+		if v, ok := (*m)[s]; ok {
+			return v, true
+		}
+		if len(*m) >= internLimit {
+			return internedValue{}, false
+		}
+	}
+	if !plainJSON(s) {
+		return internedValue{}, false
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	var cur map[string]internedValue
+	if m := in.values.Load(); m != nil {
+		cur = *m
+	}
+	if v, ok := cur[s]; ok {
+		return v, true
+	}
+	if len(cur) >= internLimit {
+		return internedValue{}, false
+	}
+	next := make(map[string]internedValue, len(cur)+1)
+	for k, v := range cur {
+		next[k] = v
+	}
+	v := internedValue{s: s, v: s, json: append(append(append(make([]byte, 0, len(s)+2), '"'), s...), '"')}
+	next[s] = v
+	in.values.Store(&next)
+	return v, true
+}
+
+// str adds the string field key with the value s to evt, from the cache when
+// s is interned.
+func (in *interner) str(evt *zerolog.Event, key, s string) *zerolog.Event {
+	if v, ok := in.intern(s); ok {
+		return evt.RawJSON(key, v.json)
+	}
+	return evt.Str(key, s)
+}
+
+// value returns b as a string boxed in an interface, reusing the cached value
+// when b is interned, which saves both allocations. With add, b is interned
+// when it is not.
+func (in *interner) value(b []byte, add bool) any {
+	if v, ok := in.lookup(b); ok {
+		return v.v
+	}
+	s := string(b)
+	if add {
+		if v, ok := in.intern(s); ok {
+			return v.v
+		}
+	}
+	return s
+}
+
+// string is like value but returns the string.
+func (in *interner) string(b []byte, add bool) string {
+	if v, ok := in.lookup(b); ok {
+		return v.s
+	}
+	s := string(b)
+	if add {
+		in.intern(s)
+	}
+	return s
+}
+
+// plainJSON reports whether s is printable ASCII without quotes or
+// backslashes, which JSON and zerolog encode as is.
+func plainJSON(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c > 0x7e || c == '"' || c == '\\' {
+			return false
+		}
+	}
+	return true
+}
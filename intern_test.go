@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterner(t *testing.T) {
+	in := &interner{}
+	v, ok := in.intern("GET")
+	assert.True(t, ok)
+	assert.Equal(t, `"GET"`, string(v.json))
+	cached, ok := in.lookup([]byte("GET"))
+	assert.True(t, ok)
+	assert.Equal(t, "GET", cached.s)
+
+	// Values needing escapes are encoded by zerolog.
+	_, ok = in.intern(`say "hi"`)
+	assert.False(t, ok)
+
+	for i := 0; len(*in.values.Load()) < internLimit; i++ {
+		in.intern("agent/" + strconv.Itoa(i))
+	}
+	_, ok = in.intern("one too many")
+	assert.False(t, ok)
+	assert.Equal(t, "one too many", in.string([]byte("one too many"), true))
+	assert.Len(t, *in.values.Load(), internLimit)
+}
+
+func TestInternerEncoding(t *testing.T) {
+	in := &interner{}
+	for _, s := range []string{"GET", "/users/:id", "Mozilla/5.0 (X11; Linux x86_64)", "café", "a\tb", `a\b`} {
+		var plain, cached bytes.Buffer
+		pl, cl := zerolog.New(&plain), zerolog.New(&cached)
+		pl.Log().Str("v", s).Send()
+		in.str(cl.Log(), "v", s).Send()
+		assert.Equal(t, plain.String(), cached.String())
+	}
+}
@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"crypto/subtle"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// routeLevelKey is the context key the level set by Level is stored under.
+const routeLevelKey = "_gin-contrib/logger_level_"
+
+// Level returns a middleware marking the routes it is registered on to be logged
+// at lvl when successful, so verbosity is tuned next to the route definition
+// rather than in a central WithPathLevel map, e.g.
+//
+//	r.GET("/healthz", logger.Level(zerolog.DebugLevel), healthz)
+//
+// It takes precedence over WithPathLevel; client and server errors keep their levels.
+func Level(lvl zerolog.Level) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(routeLevelKey, lvl)
+	}
+}
+
+// routeLevel returns the level set by Level for the request.
+func routeLevel(c *gin.Context) (zerolog.Level, bool) {
+	v, ok := c.Get(routeLevelKey)
+	if !ok {
+		return zerolog.NoLevel, false
+	}
+	lvl, ok := v.(zerolog.Level)
+	return lvl, ok
+}
+
+// Trust reports whether a request comes from a trusted caller, see WithLevelFromHeader.
+type Trust func(c *gin.Context) bool
+
+// TrustCIDRs returns a Trust accepting clients whose IP is in one of cidrs.
+// The IP is the remote address of the connection, not the one gin reads from
+// headers such as X-Forwarded-For, which any client can set. Behind a proxy,
+// list the CIDRs of the proxy or use TrustSecret. It panics if a CIDR is invalid.
+func TrustCIDRs(cidrs ...string) Trust {
+	networks := make(map[string]string, len(cidrs))
+	for _, cidr := range cidrs {
+		networks[cidr] = "trusted"
+	}
+	nc := newNetworkClassifier(networks)
+	return func(c *gin.Context) bool {
+		return nc.classify(c.RemoteIP()) == "trusted"
+	}
+}
+
+// TrustSecret returns a Trust accepting requests whose header carries secret.
+func TrustSecret(header, secret string) Trust {
+	return func(c *gin.Context) bool {
+		v := c.GetHeader(header)
+		return v != "" && subtle.ConstantTimeCompare([]byte(v), []byte(secret)) == 1
+	}
+}
+
+// headerLevel returns the level requested in header by a trusted caller.
+func headerLevel(c *gin.Context, header string, trusted Trust) (zerolog.Level, bool) {
+	v := c.GetHeader(header)
+	if v == "" || trusted == nil || !trusted(c) {
+		return zerolog.NoLevel, false
+	}
+	lvl, err := ParseLevel(v)
+	if err != nil || lvl == zerolog.NoLevel {
+		return zerolog.NoLevel, false
+	}
+	return lvl, true
+}
+
+// levelAliases maps lower-cased level names to levels, for names zerolog does
+// not know, such as the syslog severities.
+var levelAliases = struct {
+	sync.RWMutex
+	m map[string]zerolog.Level
+}{m: map[string]zerolog.Level{
+	"warning":  zerolog.WarnLevel,
+	"notice":   zerolog.InfoLevel,
+	"err":      zerolog.ErrorLevel,
+	"crit":     zerolog.FatalLevel,
+	"critical": zerolog.FatalLevel,
+	"alert":    zerolog.PanicLevel,
+	"emerg":    zerolog.PanicLevel,
+}}
+
+// RegisterLevelAlias makes ParseLevel, and so WithLevelFromHeader, map alias to
+// lvl regardless of case, e.g. journald priorities:
+//
+//	logger.RegisterLevelAlias("4", zerolog.WarnLevel)
+//
+// Aliases take precedence over zerolog's level names and numbers. The syslog
+// names "warning", "notice", "err", "crit", "critical", "alert" and "emerg" are
+// registered by default.
+func RegisterLevelAlias(alias string, lvl zerolog.Level) {
+	levelAliases.Lock()
+	defer levelAliases.Unlock()
+	levelAliases.m[strings.ToLower(alias)] = lvl
+}
+
+// levelAlias returns the level registered for the alias s.
+func levelAlias(s string) (zerolog.Level, bool) {
+	levelAliases.RLock()
+	defer levelAliases.RUnlock()
+	lvl, ok := levelAliases.m[strings.ToLower(s)]
+	return lvl, ok
+}
+
+// SeverityNumberFieldName is the field name WithSeverityNumber logs the
+// OpenTelemetry severity number under.
+var SeverityNumberFieldName = "severity_number"
+
+// SeverityNumber returns the OpenTelemetry SeverityNumber, from 1 for TRACE to 24
+// for FATAL4, of a zerolog level: the first number of the range of the matching
+// OpenTelemetry severity, with panic mapped to FATAL2 to stay above fatal. Levels
+// below trace map to 1. It returns 0, UNSPECIFIED, for zerolog.NoLevel and
+// zerolog.Disabled.
+func SeverityNumber(lvl zerolog.Level) int {
+	switch {
+	case lvl == zerolog.NoLevel || lvl == zerolog.Disabled:
+		return 0
+	case lvl <= zerolog.TraceLevel:
+		return 1
+	case lvl == zerolog.DebugLevel:
+		return 5
+	case lvl == zerolog.InfoLevel:
+		return 9
+	case lvl == zerolog.WarnLevel:
+		return 13
+	case lvl == zerolog.ErrorLevel:
+		return 17
+	case lvl == zerolog.FatalLevel:
+		return 21
+	default:
+		return 22
+	}
+}
+
+// severityHook adds the OpenTelemetry severity number of the level to every
+// event logged with a level.
+type severityHook struct{}
+
+func (severityHook) Run(e *zerolog.Event, lvl zerolog.Level, _ string) {
+	if n := SeverityNumber(lvl); n > 0 {
+		e.Int(SeverityNumberFieldName, n)
+	}
+}
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerRouteLevel(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithPathLevel(map[string]zerolog.Level{"/healthz": zerolog.WarnLevel}),
+	))
+	r.GET("/healthz", Level(zerolog.DebugLevel), func(c *gin.Context) {})
+	r.GET("/fail", Level(zerolog.DebugLevel), func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/healthz")
+	assert.Contains(t, buffer.String(), "DBG")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/fail")
+	assert.Contains(t, buffer.String(), "ERR")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "INF")
+}
+
+func TestLoggerLevelFromHeader(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	handler := func(c *gin.Context) {
+		l := Get(c)
+		l.Debug().Msg("details")
+	}
+	info := WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
+		return l.Level(zerolog.InfoLevel)
+	})
+	r.GET("/cidr", SetLogger(WithWriter(buffer), info,
+		WithLevelFromHeader("X-Log-Level", TrustCIDRs("192.0.2.0/24"))), handler)
+	r.GET("/untrusted", SetLogger(WithWriter(buffer), info,
+		WithLevelFromHeader("X-Log-Level", TrustCIDRs("10.0.0.0/8"))), handler)
+	r.GET("/secret", SetLogger(WithWriter(buffer), info,
+		WithLevelFromHeader("X-Log-Level", TrustSecret("X-Debug-Token", "t0ken"))), handler)
+
+	performRequest(r, "GET", "/cidr")
+	assert.NotContains(t, buffer.String(), "details")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/cidr", header{"X-Log-Level", "debug"})
+	assert.Contains(t, buffer.String(), "details")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/untrusted", header{"X-Log-Level", "debug"})
+	assert.NotContains(t, buffer.String(), "details")
+
+	// The remote address 192.0.2.1 is not trusted, whatever X-Forwarded-For says.
+	buffer.Reset()
+	performRequest(r, "GET", "/untrusted", header{"X-Log-Level", "debug"}, header{"X-Forwarded-For", "10.1.2.3"})
+	assert.NotContains(t, buffer.String(), "details")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/secret", header{"X-Log-Level", "debug"}, header{"X-Debug-Token", "wrong"})
+	assert.NotContains(t, buffer.String(), "details")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/secret", header{"X-Log-Level", "debug"}, header{"X-Debug-Token", "t0ken"})
+	assert.Contains(t, buffer.String(), "details")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/cidr", header{"X-Log-Level", "error"})
+	assert.Contains(t, buffer.String(), "INF")
+}
+
+func TestRegisterLevelAlias(t *testing.T) {
+	RegisterLevelAlias("Verbose", zerolog.TraceLevel)
+	t.Cleanup(func() {
+		levelAliases.Lock()
+		delete(levelAliases.m, "verbose")
+		levelAliases.Unlock()
+	})
+
+	lvl, err := ParseLevel("VERBOSE")
+	assert.NoError(t, err)
+	assert.Equal(t, zerolog.TraceLevel, lvl)
+	assert.Equal(t, zerolog.TraceLevel, MustParseLevel("verbose"))
+	assert.Equal(t, zerolog.WarnLevel, MustParseLevel("warning"))
+	assert.Panics(t, func() { MustParseLevel("loud") })
+}
+
+func TestSeverityNumber(t *testing.T) {
+	tests := map[zerolog.Level]int{
+		zerolog.Level(-3):  1,
+		zerolog.TraceLevel: 1,
+		zerolog.DebugLevel: 5,
+		zerolog.InfoLevel:  9,
+		zerolog.WarnLevel:  13,
+		zerolog.ErrorLevel: 17,
+		zerolog.FatalLevel: 21,
+		zerolog.PanicLevel: 22,
+		zerolog.NoLevel:    0,
+		zerolog.Disabled:   0,
+	}
+	for lvl, want := range tests {
+		assert.Equal(t, want, SeverityNumber(lvl), lvl.String())
+	}
+}
+
+func TestLoggerSeverityNumber(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithSeverityNumber(true)))
+	r.GET("/example", func(c *gin.Context) {
+		l := Get(c)
+		l.Warn().Msg("handler")
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "severity_number=13")
+	assert.Contains(t, buffer.String(), "severity_number=9")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/fail")
+	assert.Contains(t, buffer.String(), "severity_number=17")
+}
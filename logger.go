@@ -1,10 +1,16 @@
 package logger
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"regexp"
+	"runtime/pprof"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -23,6 +29,11 @@ type EventFn func(*gin.Context, *zerolog.Event) *zerolog.Event
 // and returns a boolean indicating whether to skip the middleware for the given context.
 type Skipper func(c *gin.Context) bool
 
+// PostSkipper defines a function to skip logging a request once the handler chain
+// returned, based on its outcome. It receives the gin.Context, the response status
+// and the latency, and returns a boolean indicating whether to skip the access line.
+type PostSkipper func(c *gin.Context, status int, latency time.Duration) bool
+
 // config holds the configuration for the logger middleware.
 type config struct {
 	// logger is a function that defines the logging behavior.
@@ -35,6 +46,14 @@ type config struct {
 	skipPath []string
 	// skipPathRegexps is a list of regular expressions to match paths to be skipped from logging.
 	skipPathRegexps []*regexp.Regexp
+	// skipPathPrefixes is a list of path prefixes to be skipped from logging.
+	skipPathPrefixes []string
+	// skipPathGlobs is a list of path.Match patterns to match paths to be skipped from logging.
+	skipPathGlobs []string
+	// onlyPaths is a list of paths to log, skipping all others when set.
+	onlyPaths []string
+	// onlyPathRegexps is a list of regular expressions to match paths to log, skipping all others when set.
+	onlyPathRegexps []*regexp.Regexp
 	// skip is a Skipper that indicates which logs should not be written. Optional.
 	skip Skipper
 	// output is a writer where logs are written. Optional. Default value is gin.DefaultWriter.
@@ -47,9 +66,175 @@ type config struct {
 	serverErrorLevel zerolog.Level
 	// pathLevels is a map of specific paths to log levels for requests with status code < 400.
 	pathLevels map[string]zerolog.Level
+	// compression is a boolean stating whether to log response compression and cache outcome fields.
+	compression bool
+	// ranges is a boolean stating whether to log range request fields.
+	ranges bool
+	// trailers is a list of HTTP trailers to log from the request and the response.
+	trailers []string
+	// bodyFields is a list of JSON paths to extract from the request body and log.
+	bodyFields []string
+	// bodyHash is the digest algorithm used to hash bodies. Nil disables body hashing.
+	bodyHash *HashAlgorithm
+	// responseBodyHash is a boolean stating whether to hash the response body as well.
+	responseBodyHash bool
+	// redactors scrub captured bodies and headers. Nil means DefaultRedactors.
+	redactors []Redactor
+	// authUser is a boolean stating whether to log the username of basic auth credentials.
+	authUser bool
+	// session is a function returning the session identifier of the request.
+	session func(*gin.Context) string
+	// rateLimit is a boolean stating whether to log rate limit outcome fields.
+	rateLimit bool
+	// cors is a boolean stating whether to log the CORS decision for cross-origin requests.
+	cors bool
+	// negotiation is a boolean stating whether to log negotiated language and encoding fields.
+	negotiation bool
+	// trafficClass is a function labelling the traffic class of the request.
+	trafficClass func(*gin.Context) string
+	// slos is a map of route templates to their service level objectives.
+	slos map[string]SLO
+	// summaryInterval is the interval between summary events. Zero disables summaries.
+	summaryInterval time.Duration
+	// metrics receives the latency observation of every logged request.
+	metrics MetricsObserver
+	// traceID is a function returning the trace identifier of the request.
+	traceID func(*gin.Context) string
+	// expvar is a boolean stating whether to publish the middleware counters via expvar.
+	expvar bool
+	// expvarPrefix is the name of the expvar map the counters are published under.
+	expvarPrefix string
+	// batch, when set, configures the batching of writes to the output.
+	batch *batchConfig
+	// asyncQueue is the size of the asynchronous writer queue. Zero writes synchronously.
+	asyncQueue int
+	// backpressure is the policy applied when the asynchronous writer queue is full.
+	backpressure BackpressurePolicy
+	// errorOutput, when set, receives warn and higher events synchronously.
+	errorOutput io.Writer
+	// crashPath is the file the recent events are dumped to on panic. Empty disables crash dumps.
+	crashPath string
+	// crashSize is the number of recent events kept for crash dumps.
+	crashSize int
+	// crashSignals are the signals triggering a crash dump.
+	crashSignals []os.Signal
+	// clock provides the current time. Default reads the system clock.
+	clock Clock
+	// sampler decides whether successful requests are logged.
+	sampler Sampler
+	// routeParams is a boolean stating whether to log the route parameters.
+	routeParams bool
+	// routeParamsAllow restricts the logged route parameters when not empty.
+	routeParamsAllow map[string]struct{}
+	// pathNormalizer rewrites the logged path, e.g. to replace IDs with placeholders.
+	pathNormalizer func(string) string
+	// devFormat is a boolean stating whether to use the multi-line development console format.
+	devFormat bool
+	// consoleColors configures console colors by status class, latency and level.
+	consoleColors *ConsoleColors
+	// autoFormat is a boolean stating whether to pick the console or JSON format from the writer.
+	autoFormat bool
+	// formatter renders events in a custom format.
+	formatter Formatter
+	// ginFormat is a boolean stating whether to replicate the gin.Logger() output.
+	ginFormat bool
+	// postSkip is a function evaluated after the handler chain to skip logging the request.
+	postSkip PostSkipper
+	// routeStats is a boolean stating whether to count requests per route for Stats.
+	routeStats bool
+	// latencyBuckets labels the latency of every request with a bucket when set.
+	latencyBuckets *latencyBuckets
+	// apdexThreshold is the Apdex target latency; zero disables Apdex.
+	apdexThreshold time.Duration
+	// throughput is the minimum transfer size in bytes for which throughput is logged;
+	// negative disables it.
+	throughput int64
+	// timingSplit is a boolean stating whether to split the latency into handler and write time.
+	timingSplit bool
+	// resolveHost is a boolean stating whether to log the reverse-resolved client host.
+	resolveHost bool
+	// resolveHostTTL is how long resolved client hosts are cached.
+	resolveHostTTL time.Duration
+	// networks classifies client IPs into the "network" field when set.
+	networks networkClassifier
+	// grpcStatus is a boolean stating whether to log the gRPC status and derive the level from it.
+	grpcStatus bool
+	// webhooks maps webhook-receiving routes to the description of their deliveries.
+	webhooks map[string]Webhook
+	// extraFields are the field extractors added by options, applied in order.
+	extraFields []EventFn
+	// conditional is a boolean stating whether to log conditional request diagnostics.
+	conditional bool
+	// levelHeader is the request header trusted callers set the request log level with.
+	levelHeader string
+	// levelHeaderTrust reports whether a caller may set the level with levelHeader.
+	levelHeaderTrust Trust
+	// baggage is the allowlist of W3C Baggage keys to log.
+	baggage map[string]struct{}
+	// envoy is a boolean stating whether to log the x-envoy-* headers.
+	envoy bool
+	// queueTime is a boolean stating whether to log the time spent queued upstream.
+	queueTime bool
+	// resourceUsage is a boolean stating whether to log approximate per-request resource usage.
+	resourceUsage bool
+	// profilerLabels is a boolean stating whether to run the handler chain with pprof labels.
+	profilerLabels bool
+	// streaming is a boolean stating whether to log the chunks and flushes of streamed responses.
+	streaming bool
+	// protocol is a boolean stating whether to log the HTTP/2 and HTTP/3 protocol fields.
+	protocol bool
+	// earlyHints is a boolean stating whether to log 1xx informational responses such as 103 Early Hints.
+	earlyHints bool
+	// superfluousWrites is a boolean stating whether to log status changes attempted after the headers were written.
+	superfluousWrites bool
+	// securityFlags is a boolean stating whether to flag suspicious requests in the "security_flags" field.
+	securityFlags bool
+	// maxPathLength is the length above which the logged path is truncated, when positive.
+	maxPathLength int
+	// decodedPath is a boolean stating whether to log the decoded, NFC-normalized path and the raw one.
+	decodedPath bool
+	// timestampAt selects whether the request log event is timestamped at arrival or completion.
+	timestampAt TimestampAt
+	// splitQuery is a boolean stating whether to log the query string in a "query" field apart from the path.
+	splitQuery bool
+	// skipMatch selects whether skip rules and path levels match the path alone or with the query string.
+	skipMatch SkipMatch
+	// matcher is a prebuilt Matcher replacing the skip and only rules of the configuration when set.
+	matcher *Matcher
+	// contextLoggerDisabled is a boolean stating whether to skip storing the request logger returned by Get.
+	contextLoggerDisabled bool
+	// controller reconfigures the levels and skip rules at runtime when set.
+	controller *Controller
+	// staticFields are the fields added to every event, encoded once.
+	staticFields map[string]any
+	// synchronous is a boolean stating whether to disable the features running goroutines.
+	synchronous bool
+	// name identifies the pipeline of the middleware in Health.
+	name string
+	// severityNumber is a boolean stating whether to log the OpenTelemetry severity number of every event.
+	severityNumber bool
+}
+
+// batchConfig holds the limits set by WithBatching.
+type batchConfig struct {
+	maxEvents int
+	maxBytes  int
+	interval  time.Duration
 }
 
-const loggerKey = "_gin-contrib/logger_"
+const (
+	// LoggerKey is the context key under which SetLogger stores a pointer to the
+	// request logger returned by Get. Prefer Get and Set over accessing it directly.
+	LoggerKey = "_gin-contrib/logger_"
+	// BaseLoggerKey is the context key under which a middleware running before
+	// SetLogger can store a zerolog.Logger. SetLogger then uses it, output included,
+	// in place of its own logger for that request. See SetBaseLogger.
+	BaseLoggerKey = "_gin-contrib/logger_base_"
+	// FieldsKey is the context key under which a middleware running before
+	// SetLogger can store a map[string]any of fields added to every event of the
+	// request. See AddFields.
+	FieldsKey = "_gin-contrib/logger_fields_"
+)
 
 var isTerm bool = isatty.IsTerminal(os.Stdout.Fd())
 
@@ -68,6 +253,7 @@ var isTerm bool = isatty.IsTerminal(os.Stdout.Fd())
 // The middleware logs the following request details:
 // - method: the HTTP method of the request.
 // - path: the URL path of the request.
+// - route: the route template matched by the request, e.g. "/users/:id".
 // - ip: the client's IP address.
 // - user_agent: the User-Agent header of the request.
 // - status: the HTTP status code of the response.
@@ -80,126 +266,676 @@ var isTerm bool = isatty.IsTerminal(os.Stdout.Fd())
 // - defaultLevel for other status codes.
 // - Custom levels can be set for specific paths using the pathLevels configuration.
 func SetLogger(opts ...Option) gin.HandlerFunc {
+	return newConfig(opts).pipeline().handler
+}
+
+// New is like SetLogger but reports options that cannot be combined, such as
+// WithSkipPath and WithPathLevel for the same path, instead of silently ignoring
+// one of them. The returned error wraps ErrOptionConflict for each conflict
+// found, in which case no middleware is returned, and ErrOptionWarning for each
+// combination that may ignore an option, such as WithWriter and WithLogger,
+// in which case the middleware is returned along with the error.
+func New(opts ...Option) (gin.HandlerFunc, error) {
+	cfg := newConfig(opts)
+	err := cfg.conflicts()
+	if errors.Is(err, ErrOptionConflict) {
+		return nil, err
+	}
+	return cfg.pipeline().handler, err
+}
+
+// ForGroup returns a middleware for a route group configured with the options of
+// its parent followed by extra, so each group of a large route tree inherits the
+// parent's writer and levels and only lists the options it overrides, such as its
+// enrichment. As with any option list, later options replace the settings of
+// earlier ones and options accumulating values, such as WithOnlyPaths, add to them.
+// The middleware is meant to replace the parent's on the group, not to run in
+// addition to it, which would log each request twice.
+func ForGroup(parent []Option, extra ...Option) gin.HandlerFunc {
+	return SetLogger(Compose(parent...), Compose(extra...))
+}
+
+// newConfig returns the default configuration with opts applied.
+func newConfig(opts []Option) *config {
 	cfg := &config{
 		defaultLevel:     zerolog.InfoLevel,
 		clientErrorLevel: zerolog.WarnLevel,
 		serverErrorLevel: zerolog.ErrorLevel,
 		output:           os.Stderr,
+		expvarPrefix:     defaultExpvarPrefix,
+		clock:            realClock{},
+		throughput:       -1,
 	}
 
-	// Apply each option to the config
+	// Apply the global defaults, then each option to the config
+	for _, o := range globalDefaults() {
+		o.apply(cfg)
+	}
 	for _, o := range opts {
 		o.apply(cfg)
 	}
+	return cfg
+}
+
+// pipeline returns the middleware for the configuration along with its writers.
+func (cfg *config) pipeline() *Pipeline {
+	p := &Pipeline{}
+	if cfg.redactors == nil {
+		cfg.redactors = DefaultRedactors()
+	}
+	if cfg.synchronous {
+		cfg.asyncQueue, cfg.batch, cfg.resolveHost, cfg.crashSignals = 0, nil, false, nil
+	}
+
+	matcher := cfg.matcher
+	if matcher == nil {
+		matcher = newMatcher(cfg)
+	}
+
+	raw := cfg.output
+	if cfg.ginFormat {
+		cfg.formatter = GinFormatter(ginColor(raw))
+	}
+	name := cfg.name
+	if name == "" {
+		name = defaultPipelineName
+	}
+	h, unregister := registerHealth(name)
+	p.health = h
+	p.release = append(p.release, unregister)
+	h.registerLevels(cfg)
+	if cfg.batch != nil {
+		b := NewBatchWriter(cfg.output, cfg.batch.maxEvents, cfg.batch.maxBytes, cfg.batch.interval)
+		b.reportTo(h)
+		h.registerQueue(b, b.Len)
+		p.flushers = append(p.flushers, b)
+		p.closers = append(p.closers, b)
+		cfg.output = b
+	} else if r, ok := cfg.output.(healthReporter); ok {
+		// The writer reports its own writes, which may fail apart from Write.
+		r.reportTo(h)
+	} else {
+		cfg.output = healthWriter{w: cfg.output, h: h}
+	}
+
+	var counters *expvar.Map
+	if cfg.expvar {
+		counters = expvarMap(cfg.expvarPrefix)
+		cfg.output = expvarWriter{w: cfg.output, m: counters}
+	}
+
+	out := cfg.console(cfg.output, raw)
+	if cfg.asyncQueue > 0 {
+		a := newAsyncWriter(out, cfg.asyncQueue, cfg.backpressure, h)
+		h.registerQueue(a, a.Len)
+		h.registerAsync(a)
+		// The queue is written to the batch, so it is flushed and closed first.
+		p.flushers = append([]flusher{a}, p.flushers...)
+		p.closers = append([]io.Closer{a}, p.closers...)
+		out = a
+	}
+	if cfg.errorOutput != nil {
+		out = levelSplitWriter{
+			min:  zerolog.WarnLevel,
+			high: cfg.console(healthWriter{w: cfg.errorOutput, h: h}, cfg.errorOutput),
+			low:  zerolog.MultiLevelWriter(out),
+		}
+	}
 
-	// Create a set of paths to skip logging
-	skip := make(map[string]struct{}, len(cfg.skipPath))
-	for _, path := range cfg.skipPath {
-		skip[path] = struct{}{}
+	var ring *ringWriter
+	if cfg.crashPath != "" {
+		ring = newRingWriter(cfg.crashPath, cfg.crashSize)
+		p.release = append(p.release, registerRing(ring, cfg.crashSignals))
+		out = zerolog.MultiLevelWriter(ring, out)
 	}
 
 	// Initialize the base logger
 	l := zerolog.New(cfg.output).
-		Output(zerolog.ConsoleWriter{Out: cfg.output, NoColor: !isTerm}).
+		Output(out).
 		With().
 		Timestamp().
 		Logger()
+	if _, ok := cfg.clock.(realClock); !ok || cfg.timestampAt == TimestampAtStart {
+		l = zerolog.New(out).Hook(clockHook{clock: cfg.clock, atStart: cfg.timestampAt == TimestampAtStart})
+	}
+	if len(cfg.staticFields) > 0 {
+		// Fields of a sub-logger are encoded once and copied into each event.
+		l = l.With().Fields(cfg.staticFields).Logger()
+	}
+	if cfg.severityNumber {
+		l = l.Hook(severityHook{})
+	}
+
+	var resolver *hostResolver
+	if cfg.resolveHost {
+		resolver = newHostResolver(cfg.resolveHostTTL, cfg.clock.Now)
+	}
+
+	var sum *summary
+	if cfg.summaryInterval > 0 {
+		sum = newSummary(cfg.summaryInterval, cfg.clock.Now())
+	}
+
+	p.handler = func(c *gin.Context) {
+		if ring != nil {
+			defer func() {
+				if err := recover(); err != nil {
+					_ = ring.dump(fmt.Sprintf("panic: %v", err))
+					panic(err)
+				}
+			}()
+		}
 
-	return func(c *gin.Context) {
 		rl := l
+		if base, ok := c.Get(BaseLoggerKey); ok {
+			if base, ok := base.(zerolog.Logger); ok {
+				rl = base
+			}
+		}
+		if fields, ok := c.Get(FieldsKey); ok {
+			if fields, ok := fields.(map[string]any); ok && len(fields) > 0 {
+				rl = rl.With().Fields(fields).Logger()
+			}
+		}
 		if cfg.logger != nil {
-			rl = cfg.logger(c, l)
+			rl = cfg.logger(c, rl)
+		}
+		if cfg.levelHeader != "" {
+			if lvl, ok := headerLevel(c, cfg.levelHeader, cfg.levelHeaderTrust); ok && lvl < rl.GetLevel() {
+				rl = rl.Level(lvl)
+			}
+		}
+
+		start := cfg.clock.Now()
+		if cfg.timestampAt == TimestampAtStart {
+			c.Set(requestStartKey, start)
+		}
+		m := matcher
+		defaultLevel, clientErrorLevel, serverErrorLevel := cfg.defaultLevel, cfg.clientErrorLevel, cfg.serverErrorLevel
+		if snap := cfg.controller.load(); snap != nil {
+			if snap.matcher != nil {
+				m = snap.matcher
+			}
+			if snap.hasLevels {
+				defaultLevel, clientErrorLevel, serverErrorLevel = snap.defaultLevel, snap.clientErrorLevel, snap.serverErrorLevel
+			}
 		}
 
-		start := time.Now()
-		path := c.Request.URL.Path
-		if raw := c.Request.URL.RawQuery; raw != "" {
-			path += "?" + raw
+		logPath, logQuery := c.Request.URL.Path, c.Request.URL.RawQuery
+		if cfg.pathNormalizer != nil {
+			logPath = cfg.pathNormalizer(logPath)
+		}
+		if !cfg.splitQuery && logQuery != "" {
+			logPath, logQuery = logPath+"?"+logQuery, ""
+		}
+		if cfg.maxPathLength > 0 {
+			logPath = truncatePath(logPath, cfg.maxPathLength)
+			logQuery = truncatePath(logQuery, cfg.maxPathLength)
 		}
 
-		track := true
-		if _, ok := skip[path]; ok || (cfg.skip != nil && cfg.skip(c)) {
+		track := !m.Skip(c.Request)
+		if track && cfg.skip != nil && cfg.skip(c) {
 			track = false
 		}
 
 		if track {
-			for _, reg := range cfg.skipPathRegexps {
-				if reg.MatchString(path) {
-					track = false
-					break
+			trackDownstream(c)
+		}
+
+		var trafficClass string
+		if track && cfg.trafficClass != nil {
+			trafficClass = cfg.trafficClass(c)
+		}
+
+		if !cfg.contextLoggerDisabled {
+			contextLogger := rl
+			if track {
+				ctx := rl.With().
+					Str("method", c.Request.Method).
+					Str("path", logPath).
+					Str("ip", c.ClientIP()).
+					Str("user_agent", c.Request.UserAgent())
+				if logQuery != "" {
+					ctx = ctx.Str("query", logQuery)
 				}
+				if trafficClass != "" {
+					ctx = ctx.Str("traffic_class", trafficClass)
+				}
+				contextLogger = ctx.Logger()
 			}
+			Set(c, contextLogger)
 		}
 
-		contextLogger := rl
-		if track {
-			contextLogger = rl.With().
-				Str("method", c.Request.Method).
-				Str("path", path).
-				Str("ip", c.ClientIP()).
-				Str("user_agent", c.Request.UserAgent()).
-				Logger()
+		var body []byte
+		if track && len(cfg.bodyFields) > 0 && isJSON(c) {
+			body, _ = readBody(c)
 		}
-		c.Set(loggerKey, contextLogger)
 
-		c.Next()
+		webhook, isWebhook := cfg.webhooks[c.FullPath()]
+		var webhookBody []byte
+		var hasWebhookBody bool
+		if track && isWebhook && webhook.Verify != nil {
+			webhookBody, hasWebhookBody = readBody(c)
+		}
 
-		if track {
-			end := time.Now()
-			if cfg.utc {
-				end = end.UTC()
+		var hr *hashingReader
+		if track && cfg.bodyHash != nil && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			hr = newHashingReader(c.Request.Body, *cfg.bodyHash)
+			c.Request.Body = hr
+		}
+
+		var mr *meteredReader
+		if track && cfg.throughput >= 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+			mr = &meteredReader{ReadCloser: c.Request.Body, now: cfg.clock.Now}
+			c.Request.Body = mr
+		}
+
+		var w *responseWriter
+		if track && (cfg.compression || cfg.throughput >= 0 || cfg.timingSplit || cfg.streaming || cfg.earlyHints || cfg.superfluousWrites || (cfg.bodyHash != nil && cfg.responseBodyHash)) {
+			w = newResponseWriter(c, cfg.clock.Now)
+			w.timing = cfg.timingSplit
+			w.hints = cfg.earlyHints
+			if cfg.bodyHash != nil && cfg.responseBodyHash {
+				w.hash = cfg.bodyHash.new()
+			}
+		}
+
+		var resourcesBefore, resourcesAfter resourceSample
+		if track && cfg.resourceUsage {
+			resourcesBefore = sampleResources()
+		}
+
+		var rc *routeCounter
+		if cfg.routeStats {
+			rc = routeStats.counter(c)
+			rc.start()
+			defer rc.done()
+		}
+
+		var labels pprof.LabelSet
+		if track && cfg.profilerLabels {
+			labels = profilerLabels(c)
+			pprof.Do(c.Request.Context(), labels, func(ctx context.Context) {
+				c.Request = c.Request.WithContext(ctx)
+				c.Next()
+			})
+		} else {
+			c.Next()
+		}
+
+		if track && cfg.resourceUsage {
+			resourcesAfter = sampleResources()
+		}
+
+		if counters != nil {
+			counters.Add("requests_total", 1)
+			if c.Writer.Status() >= http.StatusInternalServerError {
+				counters.Add("errors_total", 1)
+			}
+		}
+
+		end := cfg.clock.Now()
+		// Subtract before converting to UTC, which strips the monotonic clock reading.
+		latency := end.Sub(start)
+		latencySuspect := suspectLatency(latency)
+		if latencySuspect {
+			latency = 0
+		}
+		if cfg.utc {
+			end = end.UTC()
+		}
+
+		var traceID string
+		if cfg.traceID != nil && (track || cfg.metrics != nil) {
+			traceID = cfg.traceID(c)
+		}
+
+		// Metrics are observed whether or not the request is logged, so skip
+		// rules and sampling do not skew them.
+		var metricSeries map[string]string
+		if cfg.metrics != nil {
+			metricSeries = metricLabels(c)
+			if c.FullPath() == "" && cfg.pathNormalizer != nil {
+				metricSeries["route"] = cfg.pathNormalizer(c.Request.URL.Path)
 			}
-			latency := end.Sub(start)
+			cfg.metrics.Observe(Observation{Labels: metricSeries, Latency: latency, TraceID: traceID})
+		}
+
+		if track && cfg.postSkip != nil && cfg.postSkip(c, c.Writer.Status(), latency) {
+			track = false
+		}
+
+		if track && cfg.sampler != nil && c.Writer.Status() < http.StatusBadRequest {
+			track = cfg.sampler.Sample(c)
+		}
+
+		if rc != nil {
+			rc.add(latency, c.Writer.Status() >= http.StatusInternalServerError)
+		}
+
+		c.Set(responseInfoKey, ResponseInfo{
+			Status:  c.Writer.Status(),
+			Latency: latency,
+			Bytes:   c.Writer.Size(),
+			Outcome: outcomeOf(c.Writer.Status()),
+			Sampled: track,
+		})
+
+		if track {
+			c.Set(latencyKey, latency)
 
 			msg := "Request"
-			if len(c.Errors) > 0 {
-				msg = c.Errors.String()
+			errs := c.Errors
+			if cfg.ginFormat {
+				// gin.Logger() only prints the private errors.
+				errs = errs.ByType(gin.ErrorTypePrivate)
+			}
+			if len(errs) > 0 {
+				msg = errs.String()
 			}
 
-			var evt *zerolog.Event
-			level, hasLevel := cfg.pathLevels[path]
+			var level zerolog.Level
+			var hasLevel bool
+			if len(cfg.pathLevels) > 0 {
+				level, hasLevel = cfg.pathLevels[m.mode.target(c.Request)]
+			}
+			if lvl, ok := routeLevel(c); ok {
+				level, hasLevel = lvl, true
+			}
 
 			switch {
 			case c.Writer.Status() >= http.StatusBadRequest && c.Writer.Status() < http.StatusInternalServerError:
-				evt = rl.WithLevel(cfg.clientErrorLevel).Ctx(c)
+				level = clientErrorLevel
 			case c.Writer.Status() >= http.StatusInternalServerError:
-				evt = rl.WithLevel(cfg.serverErrorLevel).Ctx(c)
-			case hasLevel:
-				evt = rl.WithLevel(level).Ctx(c)
-			default:
-				evt = rl.WithLevel(cfg.defaultLevel).Ctx(c)
+				level = serverErrorLevel
+			case !hasLevel:
+				level = defaultLevel
+			}
+
+			grpcCode, hasGRPCCode := 0, false
+			if cfg.grpcStatus {
+				if grpcCode, hasGRPCCode = grpcStatus(c); hasGRPCCode {
+					if l, ok := grpcLevel(grpcCode, clientErrorLevel, serverErrorLevel); ok && l > level {
+						level = l
+					}
+				}
+			}
+
+			var sloBreach bool
+			if slo, ok := cfg.slos[routeOf(c)]; ok {
+				sloBreach = slo.breached(c.Writer.Status(), latency)
+				if sloBreach && level < zerolog.WarnLevel {
+					level = zerolog.WarnLevel
+				}
+				if sum != nil {
+					sum.recordSLO(routeOf(c), sloBreach)
+				}
+			}
+
+			evt := rl.WithLevel(level).Ctx(c)
+			if sloBreach {
+				evt = evt.Bool("slo_breach", true)
 			}
 
 			if cfg.context != nil {
 				evt = cfg.context(c, evt)
 			}
 
-			evt.
-				Int("status", c.Writer.Status()).
-				Str("method", c.Request.Method).
-				Str("path", path).
-				Str("ip", c.ClientIP()).
-				Dur("latency", latency).
-				Str("user_agent", c.Request.UserAgent()).
+			if c.FullPath() != "" {
+				evt = interned.str(evt, "route", c.FullPath())
+			}
+
+			for _, fn := range cfg.extraFields {
+				evt = fn(c, evt)
+			}
+
+			if errs := recordedErrors(c); errs != nil {
+				evt = evt.Array("errors", errs)
+			}
+
+			evt = downstreamFields(c, evt)
+			evt = annotationFields(c, evt)
+
+			if isWebhook {
+				evt = webhookFields(c, evt, webhook, webhookBody, hasWebhookBody)
+			}
+
+			if hasGRPCCode {
+				evt = evt.Str("grpc_code", grpcCodeName(grpcCode))
+			}
+
+			if cfg.protocol {
+				evt = protocolFields(c, evt)
+			}
+
+			if cfg.decodedPath {
+				evt = evt.Str("decoded_path", decodedPath(c.Request.URL)).Str("raw_path", rawPath(c.Request))
+			}
+
+			if cfg.securityFlags {
+				if flags := securityFlags(c.Request); len(flags) > 0 {
+					evt = evt.Strs("security_flags", flags)
+				}
+			}
+
+			if cfg.networks != nil {
+				evt = evt.Str("network", cfg.networks.classify(c.ClientIP()))
+			}
+
+			if resolver != nil {
+				if host, ok := resolver.host(c.ClientIP()); ok {
+					evt = evt.Str("client_host", host)
+				}
+			}
+
+			if cfg.authUser {
+				if user, ok := authUser(c); ok {
+					evt = evt.Str("auth_user", user)
+				}
+			}
+
+			if trafficClass != "" {
+				evt = evt.Str("traffic_class", trafficClass)
+			}
+
+			if traceID != "" {
+				evt = evt.Str("trace_id", traceID)
+			}
+
+			if metricSeries != nil {
+				evt = evt.Dict("metric_labels", labelsDict(metricSeries))
+			}
+
+			if cfg.session != nil {
+				if id := cfg.session(c); id != "" {
+					evt = evt.Str("session_id", id)
+				}
+			}
+
+			if cfg.apdexThreshold > 0 {
+				class := apdexClass(cfg.apdexThreshold, c.Writer.Status(), latency)
+				evt = evt.Str("apdex", class)
+				if sum != nil {
+					sum.recordApdex(class)
+				}
+			}
+
+			if cfg.latencyBuckets != nil {
+				evt = evt.Str("latency_bucket", cfg.latencyBuckets.bucket(latency))
+			}
+
+			if cfg.superfluousWrites && w.superfluous != 0 {
+				evt = evt.Int("superfluous_write", w.superfluous)
+			}
+
+			if cfg.earlyHints && w.informational != 0 {
+				evt = evt.Int("informational_status", w.informational).Dur("hint_latency", w.hintAt.Sub(start))
+			}
+
+			if cfg.streaming && (w.flushes > 0 || w.chunks > 1) {
+				evt = evt.Int("chunks", w.chunks).Int("flushes", w.flushes)
+			}
+
+			if cfg.timingSplit {
+				evt = evt.Dur("handler_time", latency-w.writeTime).Dur("write_time", w.writeTime)
+			}
+
+			if cfg.throughput >= 0 {
+				evt = throughputFields(c, evt, mr, w, cfg.throughput, start, end)
+			}
+
+			if cfg.compression {
+				evt = compressionFields(c, evt, w)
+			}
+
+			if cfg.conditional {
+				evt = conditionalFields(c, evt)
+			}
+
+			if cfg.ranges {
+				evt = rangeFields(c, evt)
+			}
+
+			if cfg.rateLimit {
+				evt = rateLimitFields(c, evt)
+			}
+
+			if cfg.cors {
+				evt = corsFields(c, evt)
+			}
+
+			if cfg.negotiation {
+				evt = negotiationFields(c, evt)
+			}
+
+			if cfg.profilerLabels {
+				evt = profilerLabelsFields(evt, labels)
+			}
+
+			if cfg.resourceUsage {
+				evt = resourceFields(evt, resourcesBefore, resourcesAfter)
+			}
+
+			if cfg.queueTime {
+				if queued, ok := requestStart(c); ok && !start.Before(queued) {
+					evt = evt.Dur("queue_time", start.Sub(queued))
+				}
+			}
+
+			if cfg.envoy {
+				evt = envoyFields(c, evt)
+			}
+
+			if len(cfg.baggage) > 0 {
+				evt = baggageFields(c, evt, cfg.baggage, cfg.redactors)
+			}
+
+			if cfg.routeParams {
+				evt = routeParamsFields(c, evt, cfg.routeParamsAllow, cfg.redactors)
+			}
+
+			if len(cfg.trailers) > 0 {
+				evt = trailerFields(c, evt, cfg.trailers, cfg.redactors)
+			}
+
+			if len(cfg.bodyFields) > 0 && body != nil {
+				if dict := bodyFields(body, cfg.bodyFields, cfg.redactors); dict != nil {
+					evt = evt.Dict("body", dict)
+				}
+			}
+
+			if hr != nil {
+				if sum, ok := hr.sum(); ok {
+					evt = evt.Str("body_hash", sum)
+				}
+			}
+			if w != nil && w.hash != nil {
+				evt = evt.Str("response_body_hash", hex.EncodeToString(w.hash.Sum(nil)))
+			}
+
+			evt = interned.str(evt.Int("status", c.Writer.Status()), "method", c.Request.Method).
+				Str("path", logPath).
+				Str("ip", c.ClientIP())
+			if logQuery != "" {
+				evt = evt.Str("query", logQuery)
+			}
+			if latencySuspect {
+				evt = evt.Bool("latency_suspect", true)
+			} else {
+				evt = evt.Dur("latency", latency)
+			}
+			interned.str(evt, "user_agent", c.Request.UserAgent()).
 				Int("body_size", c.Writer.Size()).
 				Msg(msg)
+
+			if sum != nil {
+				sum.flush(l, cfg.slos, end)
+			}
+		}
+	}
+	registerPipeline(p)
+	return p
+}
+
+// console returns the writer formatting events to w, which wraps the configured
+// writer dst. It is the formatter when one is set, and otherwise human readable
+// unless WithAutoFormat is set and dst is not a terminal.
+func (cfg *config) console(w, dst io.Writer) io.Writer {
+	if cfg.formatter != nil {
+		return formatWriter{out: w, fn: cfg.formatter}
+	}
+	noColor := !isTerm
+	if cfg.autoFormat {
+		if !isTerminal(dst) {
+			return w
 		}
+		noColor = false
+	}
+	if cfg.devFormat {
+		return devWriter{out: w, noColor: noColor, colors: cfg.consoleColors}
 	}
+	if cfg.consoleColors != nil {
+		return colorWriter{out: w, noColor: noColor, colors: cfg.consoleColors}
+	}
+	if noColor {
+		return newPlainConsoleWriter(w)
+	}
+	return zerolog.ConsoleWriter{Out: w, NoColor: noColor}
+}
+
+// isTerminal reports whether w is a file descriptor attached to a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
 }
 
 // ParseLevel parses a string representation of a log level and returns the corresponding zerolog.Level.
 // It takes a single argument:
-//   - levelStr: a string representing the log level (e.g., "debug", "info", "warn", "error").
+//   - levelStr: a string representing the log level (e.g., "debug", "info", "warn", "error"),
+//     or an alias such as "warning" or one registered with RegisterLevelAlias, in any case.
 //
 // It returns:
 //   - zerolog.Level: the parsed log level.
 //   - error: an error if the log level string is invalid.
 func ParseLevel(levelStr string) (zerolog.Level, error) {
+	if lvl, ok := levelAlias(levelStr); ok {
+		return lvl, nil
+	}
 	return zerolog.ParseLevel(levelStr)
 }
 
+// MustParseLevel is like ParseLevel but panics if levelStr is invalid, for levels
+// set in code or configuration validated at startup.
+func MustParseLevel(levelStr string) zerolog.Level {
+	lvl, err := ParseLevel(levelStr)
+	if err != nil {
+		panic(fmt.Sprintf("logger: %v", err))
+	}
+	return lvl
+}
+
 // Get retrieves the zerolog.Logger instance from the given gin.Context.
-// It assumes that the logger has been previously set in the context with the key loggerKey.
+// It assumes that the logger has been previously set in the context with the key LoggerKey.
 // If the logger is not found, it will panic.
 //
 // Parameters:
@@ -210,5 +946,59 @@ func ParseLevel(levelStr string) (zerolog.Level, error) {
 //
 //	zerolog.Logger - the logger instance stored in the context.
 func Get(c *gin.Context) zerolog.Logger {
-	return c.MustGet(loggerKey).(zerolog.Logger)
+	return *GetPtr(c)
+}
+
+// GetPtr is like Get but returns a pointer to the request logger instead of a copy,
+// so handlers calling it often, or calling methods with pointer receivers such as
+// Info, neither copy the logger nor need a local variable. The logger is shared by
+// the handlers of the request: use Set rather than writing through the pointer to
+// replace it.
+func GetPtr(c *gin.Context) *zerolog.Logger {
+	v := c.MustGet(LoggerKey)
+	if l, ok := v.(*zerolog.Logger); ok {
+		return l
+	}
+	l := v.(zerolog.Logger)
+	return &l
+}
+
+// Set replaces the request logger returned by Get for the handlers that follow,
+// so a middleware can wrap or enrich it mid-chain, e.g.
+//
+//	Set(c, Get(c).With().Str("user", id).Logger())
+func Set(c *gin.Context, l zerolog.Logger) {
+	c.Set(LoggerKey, &l)
+}
+
+// SetBaseLogger makes SetLogger use l as the base logger of the request, so
+// middleware such as authentication or tenancy resolution can contribute a
+// prepared logger without depending on the logger configuration.
+// It must be called before SetLogger runs.
+func SetBaseLogger(c *gin.Context, l zerolog.Logger) {
+	c.Set(BaseLoggerKey, l)
+}
+
+// AddFields adds fields to every event SetLogger writes for the request,
+// including those written through Get(c). Repeated calls merge the fields.
+// It must be called before SetLogger runs.
+func AddFields(c *gin.Context, fields map[string]any) {
+	merged := make(map[string]any, len(fields))
+	if v, ok := c.Get(FieldsKey); ok {
+		if existing, ok := v.(map[string]any); ok {
+			for k, v := range existing {
+				merged[k] = v
+			}
+		}
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	c.Set(FieldsKey, merged)
+}
+
+// Component returns a child of the request logger tagged with a "component"
+// field, so layered code derives sub-loggers that keep the request fields.
+func Component(c *gin.Context, name string) zerolog.Logger {
+	return Get(c).With().Str("component", name).Logger()
 }
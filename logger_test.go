@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"regexp"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -234,6 +236,10 @@ func TestLoggerParseLevel(t *testing.T) {
 		{"-1", args{"-1"}, zerolog.TraceLevel, false},
 		{"-2", args{"-2"}, zerolog.Level(-2), false},
 		{"-3", args{"-3"}, zerolog.Level(-3), false},
+		{"warning", args{"WARNING"}, zerolog.WarnLevel, false},
+		{"critical", args{"critical"}, zerolog.FatalLevel, false},
+		{"notice", args{"notice"}, zerolog.InfoLevel, false},
+		{"invalid", args{"verbose"}, zerolog.NoLevel, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -310,6 +316,27 @@ func TestLoggerSkipper(t *testing.T) {
 	assert.NotContains(t, buffer.String(), "/example2")
 }
 
+func TestLoggerPostSkipper(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithPostSkipper(func(c *gin.Context, status int, latency time.Duration) bool {
+			return status < http.StatusBadRequest && latency < time.Second
+		}),
+	))
+	r.GET("/example", func(c *gin.Context) {})
+	r.GET("/error", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	performRequest(r, "GET", "/example")
+	assert.Empty(t, buffer.String())
+
+	performRequest(r, "GET", "/error")
+	assert.Contains(t, buffer.String(), "/error")
+	assert.Contains(t, buffer.String(), "status=500")
+}
+
 func BenchmarkLogger(b *testing.B) {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
@@ -334,3 +361,204 @@ func BenchmarkLogger(b *testing.B) {
 		}
 	})
 }
+
+func TestLoggerBaseLoggerFromContext(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	base := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if c.GetHeader("X-Tenant") != "" {
+			AddFields(c, map[string]any{"tenant": c.GetHeader("X-Tenant")})
+			AddFields(c, map[string]any{"plan": "pro"})
+		}
+		if c.GetHeader("X-Base") != "" {
+			SetBaseLogger(c, zerolog.New(base).With().Str("auth", "oidc").Logger())
+		}
+	})
+	r.Use(SetLogger(WithWriter(buffer)))
+	r.GET("/example", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler")
+	})
+
+	performRequest(r, "GET", "/example", header{"X-Tenant", "acme"})
+	assert.Equal(t, 2, strings.Count(buffer.String(), "tenant=acme"))
+	assert.Equal(t, 2, strings.Count(buffer.String(), "plan=pro"))
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example", header{"X-Base", "1"})
+	assert.Empty(t, buffer.String())
+	assert.Equal(t, 2, strings.Count(base.String(), `"auth":"oidc"`))
+}
+
+func TestLoggerSetMidChain(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer)))
+	r.Use(func(c *gin.Context) {
+		Set(c, Get(c).With().Str("user", "jane").Logger())
+	})
+	r.GET("/example", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler")
+		assert.Equal(t, &l, c.MustGet(LoggerKey))
+		assert.Equal(t, &l, GetPtr(c))
+	})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "handler ip=192.0.2.1 method=GET path=/example user=jane")
+}
+
+func TestLoggerComponent(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer)))
+	r.GET("/example", func(c *gin.Context) {
+		l := Component(c, "payment-service")
+		l.Info().Msg("charged")
+	})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "charged component=payment-service ip=192.0.2.1 method=GET path=/example")
+}
+
+func TestLoggerAutoFormat(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	f, err := os.CreateTemp(t.TempDir(), "access.log")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/buffer", SetLogger(WithWriter(buffer), WithAutoFormat()), func(c *gin.Context) {})
+	r.GET("/file", SetLogger(WithWriter(f), WithAutoFormat()), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/buffer")
+	assert.Contains(t, buffer.String(), `"status":200`)
+	assert.Contains(t, buffer.String(), `"path":"/buffer"`)
+
+	performRequest(r, "GET", "/file")
+	b, err := os.ReadFile(f.Name())
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"path":"/file"`)
+	assert.False(t, isTerminal(f))
+	assert.False(t, isTerminal(buffer))
+}
+
+func TestSetGlobalDefaults(t *testing.T) {
+	defaultBuffer := new(bytes.Buffer)
+	callBuffer := new(bytes.Buffer)
+	SetGlobalDefaults(
+		WithWriter(defaultBuffer),
+		WithSkipPath([]string{"/health"}),
+	)
+	defer SetGlobalDefaults()
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/default", SetLogger(), func(c *gin.Context) {})
+	r.GET("/override", SetLogger(WithWriter(callBuffer)), func(c *gin.Context) {})
+	r.GET("/health", SetLogger(), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/default")
+	performRequest(r, "GET", "/override")
+	performRequest(r, "GET", "/health")
+	assert.Contains(t, defaultBuffer.String(), "/default")
+	assert.NotContains(t, defaultBuffer.String(), "/override")
+	assert.NotContains(t, defaultBuffer.String(), "/health")
+	assert.Contains(t, callBuffer.String(), "/override")
+
+	SetGlobalDefaults()
+	assert.Empty(t, globalDefaults())
+}
+
+func TestCompose(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	base := Compose(
+		WithSkipPath([]string{"/health"}),
+		WithDefaultLevel(zerolog.DebugLevel),
+	)
+	standard := Compose(base, WithWriter(buffer))
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(standard, WithDefaultLevel(zerolog.WarnLevel)))
+	r.GET("/example", func(c *gin.Context) {})
+	r.GET("/health", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	performRequest(r, "GET", "/health")
+	assert.Contains(t, buffer.String(), "WRN")
+	assert.Contains(t, buffer.String(), "/example")
+	assert.NotContains(t, buffer.String(), "/health")
+}
+
+func TestForGroup(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	parent := []Option{WithWriter(buffer), WithDefaultLevel(zerolog.DebugLevel)}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	api := r.Group("/api", ForGroup(parent, WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
+		return l.With().Str("group", "api").Logger()
+	})))
+	api.GET("/users", func(c *gin.Context) {})
+	admin := r.Group("/admin", ForGroup(parent, WithDefaultLevel(zerolog.WarnLevel)))
+	admin.GET("/users", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/api/users")
+	assert.Contains(t, buffer.String(), "DBG")
+	assert.Contains(t, buffer.String(), "group=api")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/admin/users")
+	assert.Contains(t, buffer.String(), "WRN")
+	assert.NotContains(t, buffer.String(), "group=api")
+	assert.Len(t, parent, 2)
+}
+
+func TestGetPtrValueLogger(t *testing.T) {
+	c, _ := gin.CreateTestContext(nil)
+	buffer := new(bytes.Buffer)
+	c.Set(LoggerKey, zerolog.New(buffer))
+	GetPtr(c).Info().Msg("stored by value")
+	assert.Contains(t, buffer.String(), "stored by value")
+}
+
+func TestLoggerContextLoggerDisabled(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithContextLoggerDisabled(true)))
+	r.GET("/example", func(c *gin.Context) {
+		_, ok := c.Get(LoggerKey)
+		assert.False(t, ok)
+	})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "path=/example")
+}
+
+func TestLoggerStaticFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithStaticFields(ServiceFields("api", "1.2.3")),
+		WithStaticFields(map[string]any{"region": "eu-west-1"}),
+	))
+	r.GET("/example", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler")
+	})
+
+	performRequest(r, "GET", "/example")
+	assert.Equal(t, 2, strings.Count(buffer.String(), "service=api"))
+	assert.Equal(t, 2, strings.Count(buffer.String(), "region=eu-west-1"))
+	assert.Contains(t, buffer.String(), "version=1.2.3")
+	assert.Contains(t, buffer.String(), "hostname=")
+}
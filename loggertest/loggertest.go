@@ -0,0 +1,211 @@
+// Package loggertest provides a log recorder and assertion helpers for testing
+// code that uses the gin-contrib/logger middleware, so tests can check the
+// structured fields of emitted events instead of string-matching console output.
+package loggertest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-contrib/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// Entry is a single event recorded by a Recorder.
+type Entry struct {
+	// Level is the level name of the event, e.g. "info".
+	Level string
+	// Message is the message of the event.
+	Message string
+	// Fields holds every field of the event, as decoded by encoding/json.
+	Fields map[string]any
+}
+
+// Status returns the "status" field of the entry, or 0 when it has none.
+func (e Entry) Status() int {
+	v, _ := e.Fields["status"].(float64)
+	return int(v)
+}
+
+// Recorder is an io.Writer that parses the JSON events written to it into entries.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Write parses the newline-delimited JSON events in p. It fails on anything
+// else, which usually means the middleware still writes console output.
+func (r *Recorder) Write(p []byte) (int, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	var entries []Entry
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		fields := make(map[string]any)
+		if err := json.Unmarshal(line, &fields); err != nil {
+			return 0, fmt.Errorf("loggertest: event is not JSON: %w", err)
+		}
+		level, _ := fields[zerolog.LevelFieldName].(string)
+		msg, _ := fields[zerolog.MessageFieldName].(string)
+		entries = append(entries, Entry{Level: level, Message: msg, Fields: fields})
+	}
+
+	r.mu.Lock()
+	r.entries = append(r.entries, entries...)
+	r.mu.Unlock()
+	return len(p), nil
+}
+
+// Entries returns a copy of the recorded entries.
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Reset discards the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Option returns a logger.Option making SetLogger write JSON events to the recorder.
+func (r *Recorder) Option() logger.Option {
+	return logger.WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
+		return l.Output(r)
+	})
+}
+
+// Matcher selects entries in assertions.
+type Matcher interface {
+	Match(e Entry) bool
+	String() string
+}
+
+type matcher struct {
+	desc string
+	fn   func(Entry) bool
+}
+
+func (m matcher) Match(e Entry) bool { return m.fn(e) }
+func (m matcher) String() string     { return m.desc }
+
+// MatchStatus matches entries whose status field equals code.
+func MatchStatus(code int) Matcher {
+	return matcher{fmt.Sprintf("status=%d", code), func(e Entry) bool {
+		return e.Status() == code
+	}}
+}
+
+// MatchLevel matches entries logged at level.
+func MatchLevel(level zerolog.Level) Matcher {
+	return matcher{"level=" + level.String(), func(e Entry) bool {
+		return e.Level == level.String()
+	}}
+}
+
+// MatchMessage matches entries with the message msg.
+func MatchMessage(msg string) Matcher {
+	return matcher{fmt.Sprintf("message=%q", msg), func(e Entry) bool {
+		return e.Message == msg
+	}}
+}
+
+// MatchField matches entries whose field key equals value once value is
+// converted to its JSON representation, so MatchField("status", 200) and
+// MatchField("cors", map[string]any{"allowed": true}) work as expected.
+func MatchField(key string, value any) Matcher {
+	want := normalize(value)
+	return matcher{fmt.Sprintf("%s=%v", key, value), func(e Entry) bool {
+		got, ok := e.Fields[key]
+		return ok && reflect.DeepEqual(got, want)
+	}}
+}
+
+// MatchHasField matches entries having the field key, whatever its value.
+func MatchHasField(key string) Matcher {
+	return matcher{key + " present", func(e Entry) bool {
+		_, ok := e.Fields[key]
+		return ok
+	}}
+}
+
+func normalize(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// TestingT is the subset of testing.T used by the assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// Find returns the entries matching all matchers.
+func Find(r *Recorder, matchers ...Matcher) []Entry {
+	var found []Entry
+	for _, e := range r.Entries() {
+		if matchAll(e, matchers) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// AssertLogged checks that at least one recorded entry matches all matchers.
+func AssertLogged(t TestingT, r *Recorder, matchers ...Matcher) bool {
+	t.Helper()
+	if len(Find(r, matchers...)) > 0 {
+		return true
+	}
+	t.Errorf("no entry matches %s among %d entries", describe(matchers), len(r.Entries()))
+	return false
+}
+
+// AssertNotLogged checks that no recorded entry matches all matchers.
+func AssertNotLogged(t TestingT, r *Recorder, matchers ...Matcher) bool {
+	t.Helper()
+	if n := len(Find(r, matchers...)); n > 0 {
+		t.Errorf("%d entries match %s", n, describe(matchers))
+		return false
+	}
+	return true
+}
+
+func matchAll(e Entry, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m.Match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+func describe(matchers []Matcher) string {
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		parts[i] = m.String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,61 @@
+package loggertest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(logger.SetLogger(rec.Option(), logger.WithCORSFields(true)))
+	r.GET("/x", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest("GET", "/x", nil)
+	req.Header.Set("Origin", "https://a.example")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Len(t, rec.Entries(), 1)
+	AssertLogged(t, rec, MatchStatus(500), MatchField("path", "/x"), MatchLevel(zerolog.ErrorLevel))
+	AssertLogged(t, rec, MatchField("cors", map[string]any{
+		"origin": "https://a.example", "preflight": false, "allowed": false,
+	}))
+	AssertLogged(t, rec, MatchMessage("Request"), MatchHasField("latency"))
+	AssertNotLogged(t, rec, MatchStatus(200))
+
+	ft := &fakeT{}
+	assert.False(t, AssertLogged(ft, rec, MatchStatus(200), MatchField("path", "/y")))
+	assert.Equal(t, []string{"no entry matches [status=200, path=/y] among 1 entries"}, ft.errors)
+
+	ft = &fakeT{}
+	assert.False(t, AssertNotLogged(ft, rec, MatchStatus(500)))
+	assert.Len(t, ft.errors, 1)
+
+	rec.Reset()
+	assert.Empty(t, rec.Entries())
+}
+
+func TestRecorderRejectsConsoleOutput(t *testing.T) {
+	_, err := NewRecorder().Write([]byte("3:04PM INF Request status=200\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// Observation is the measurement of a single request passed to a MetricsObserver.
+type Observation struct {
+	// Labels identifies the metric series: "route", "method" and "status_class".
+	Labels map[string]string
+	// Latency is the time taken to process the request.
+	Latency time.Duration
+	// TraceID is the trace of the request when WithTraceID is configured, to be
+	// attached as an exemplar so dashboards can jump from a latency spike to the
+	// trace and to the log lines carrying the same trace_id.
+	TraceID string
+}
+
+// MetricsObserver receives an Observation for every request, typically
+// to feed a latency histogram, e.g. with prometheus' ObserveWithExemplar.
+type MetricsObserver interface {
+	Observe(o Observation)
+}
+
+// MetricsObserverFunc is an adapter to allow the use of ordinary functions as MetricsObserver.
+type MetricsObserverFunc func(o Observation)
+
+// Observe calls f(o).
+func (f MetricsObserverFunc) Observe(o Observation) {
+	f(o)
+}
+
+// metricLabels returns the series labels of the request. Requests that did not
+// match a route share the route label unmatchedRoute, so stray paths do not
+// create series.
+func metricLabels(c *gin.Context) map[string]string {
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	return map[string]string{
+		"route":        route,
+		"method":       c.Request.Method,
+		"status_class": strconv.Itoa(c.Writer.Status()/100) + "xx",
+	}
+}
+
+// labelsDict renders metric labels as a log group.
+func labelsDict(labels map[string]string) *zerolog.Event {
+	dict := zerolog.Dict()
+	for k, v := range labels {
+		dict = dict.Str(k, v)
+	}
+	return dict
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerMetricsExemplar(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	var observed []Observation
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithMetrics(MetricsObserverFunc(func(o Observation) {
+			observed = append(observed, o)
+		})),
+		WithTraceID(func(c *gin.Context) string {
+			return c.GetHeader("X-Trace")
+		}),
+	))
+	r.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	performRequest(r, "GET", "/users/1", header{"X-Trace", "4bf92f3577b34da6a3ce929d0e0e4736"})
+	assert.Len(t, observed, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", observed[0].TraceID)
+	assert.Equal(t, map[string]string{"route": "/users/:id", "method": "GET", "status_class": "4xx"}, observed[0].Labels)
+	assert.Contains(t, buffer.String(), "trace_id=4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Contains(t, buffer.String(), `metric_labels={"method":"GET","route":"/users/:id","status_class":"4xx"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/users/2")
+	assert.Len(t, observed, 2)
+	assert.Empty(t, observed[1].TraceID)
+	assert.NotContains(t, buffer.String(), "trace_id")
+}
+
+func TestLoggerMetricsUnloggedRequests(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	var observed []Observation
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithMetrics(MetricsObserverFunc(func(o Observation) {
+			observed = append(observed, o)
+		})),
+		WithSkipPath([]string{"/health"}),
+		WithSampler(SamplerFunc(func(c *gin.Context) bool { return false })),
+	))
+	r.GET("/health", func(c *gin.Context) {})
+	r.GET("/users/:id", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/health")
+	performRequest(r, "GET", "/users/1")
+	performRequest(r, "GET", "/missing/1")
+	performRequest(r, "GET", "/missing/2")
+	assert.Len(t, observed, 4)
+	assert.Equal(t, "/health", observed[0].Labels["route"])
+	assert.Equal(t, "/users/:id", observed[1].Labels["route"])
+	assert.Equal(t, unmatchedRoute, observed[2].Labels["route"])
+	assert.Equal(t, unmatchedRoute, observed[3].Labels["route"])
+	assert.NotContains(t, buffer.String(), "/health")
+	assert.NotContains(t, buffer.String(), "/users/1")
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+)
+
+// MQTTQoS is the MQTT quality of service level events are published with.
+type MQTTQoS byte
+
+const (
+	// MQTTAtMostOnce publishes without acknowledgement: events are lost when the
+	// broker is unreachable.
+	MQTTAtMostOnce MQTTQoS = iota
+	// MQTTAtLeastOnce publishes until the broker acknowledges, so events may be
+	// delivered twice.
+	MQTTAtLeastOnce
+	// MQTTExactlyOnce publishes with the four-step handshake of QoS 2.
+	MQTTExactlyOnce
+)
+
+// MQTTPublisher publishes a message to an MQTT broker. Implement it with the
+// client of your choice, e.g. with the Eclipse Paho client:
+//
+//	logger.MQTTPublisherFunc(func(topic string, qos logger.MQTTQoS, payload []byte) error {
+//		token := client.Publish(topic, byte(qos), false, payload)
+//		token.Wait()
+//		return token.Error()
+//	})
+type MQTTPublisher interface {
+	Publish(topic string, qos MQTTQoS, payload []byte) error
+}
+
+// MQTTPublisherFunc is an adapter to allow the use of ordinary functions as MQTTPublisher.
+type MQTTPublisherFunc func(topic string, qos MQTTQoS, payload []byte) error
+
+// Publish calls f(topic, qos, payload).
+func (f MQTTPublisherFunc) Publish(topic string, qos MQTTQoS, payload []byte) error {
+	return f(topic, qos, payload)
+}
+
+// MQTTWriter publishes each event as one message on an MQTT topic, for
+// deployments where the rest of the telemetry already flows over MQTT. Events
+// must be written one per Write call, which is what zerolog does. Write blocks
+// for as long as Publish does: wrap the writer in an AsyncWriter, or use
+// WithAsync, to keep the broker off the request path.
+type MQTTWriter struct {
+	pub   MQTTPublisher
+	topic string
+	qos   MQTTQoS
+}
+
+// NewMQTTWriter returns an MQTTWriter publishing to topic through pub with qos.
+func NewMQTTWriter(pub MQTTPublisher, topic string, qos MQTTQoS) *MQTTWriter {
+	return &MQTTWriter{pub: pub, topic: topic, qos: qos}
+}
+
+// Write publishes the event p without its trailing newline. The payload is a
+// copy, so the publisher may keep it after Write returned.
+func (w *MQTTWriter) Write(p []byte) (int, error) {
+	payload := bytes.Clone(bytes.TrimRight(p, "\n"))
+	if err := w.pub.Publish(w.topic, w.qos, payload); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
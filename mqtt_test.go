@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMQTTWriter(t *testing.T) {
+	var topics []string
+	var payloads [][]byte
+	var qos MQTTQoS
+	w := NewMQTTWriter(MQTTPublisherFunc(func(topic string, q MQTTQoS, payload []byte) error {
+		topics = append(topics, topic)
+		payloads = append(payloads, payload)
+		qos = q
+		return nil
+	}), "gin/access", MQTTAtLeastOnce)
+
+	p := []byte(`{"status":200}` + "\n")
+	n, err := w.Write(p)
+	assert.NoError(t, err)
+	assert.Equal(t, len(p), n)
+	// zerolog reuses its buffers: the payload must not change with them.
+	copy(p, "xxxxxxxx")
+	assert.Equal(t, []string{"gin/access"}, topics)
+	assert.Equal(t, [][]byte{[]byte(`{"status":200}`)}, payloads)
+	assert.Equal(t, MQTTAtLeastOnce, qos)
+
+	w = NewMQTTWriter(MQTTPublisherFunc(func(string, MQTTQoS, []byte) error {
+		return errors.New("not connected")
+	}), "gin/access", MQTTAtMostOnce)
+	_, err = w.Write(p)
+	assert.EqualError(t, err, "not connected")
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NATSPublisher publishes a message on a NATS subject. A *nats.Conn of the
+// official client implements it.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublisherFunc is an adapter to allow the use of ordinary functions as NATSPublisher.
+type NATSPublisherFunc func(subject string, data []byte) error
+
+// Publish calls f(subject, data).
+func (f NATSPublisherFunc) Publish(subject string, data []byte) error {
+	return f(subject, data)
+}
+
+// NATSWriter publishes each event on a NATS subject, so consumers such as
+// alerting or anomaly detection receive requests in real time and can
+// subscribe to the part of the traffic they care about, e.g.
+// "access.5xx.>" for server errors.
+//
+// The subject is a template where {name} is replaced with the value of the
+// top-level field name of the event and {status_class} with the class of its
+// "status", e.g. "5xx". Values are made valid subject tokens, turning the
+// "route" "/users/:id" into "users._id"; missing fields become "_", such as the
+// route of requests not matching one.
+//
+// Events must be JSON objects, one per Write call, which is what zerolog
+// produces when it is not wrapped in a ConsoleWriter, e.g. with WithAutoFormat.
+// A subject without fields is used as is, whatever the format. Write blocks for
+// as long as Publish does.
+type NATSWriter struct {
+	pub     NATSPublisher
+	subject []string
+}
+
+// NewNATSWriter returns a NATSWriter publishing through pub on the subject
+// template subject.
+func NewNATSWriter(pub NATSPublisher, subject string) *NATSWriter {
+	return &NATSWriter{pub: pub, subject: parseSubject(subject)}
+}
+
+// parseSubject splits the template s into literal text at even indexes and
+// field names at odd indexes.
+func parseSubject(s string) []string {
+	var parts []string
+	for {
+		i := strings.IndexByte(s, '{')
+		j := strings.IndexByte(s[i+1:], '}')
+		if i < 0 || j < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i], s[i+1:i+1+j])
+		s = s[i+2+j:]
+	}
+}
+
+// Write publishes the event p, without its trailing newline, on its subject.
+// The payload is a copy, so the publisher may keep it after Write returned.
+func (w *NATSWriter) Write(p []byte) (int, error) {
+	data := bytes.Clone(bytes.TrimRight(p, "\n"))
+	subject := w.subject[0]
+	if len(w.subject) > 1 {
+		fields := map[string]any{}
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		if err := d.Decode(&fields); err != nil {
+			return 0, fmt.Errorf("logger: nats writer expects one JSON object per write: %w", err)
+		}
+		var b strings.Builder
+		for i, part := range w.subject {
+			if i%2 == 0 {
+				b.WriteString(part)
+			} else {
+				b.WriteString(subjectToken(subjectField(fields, part)))
+			}
+		}
+		subject = b.String()
+	}
+	if err := w.pub.Publish(subject, data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// subjectField returns the value of the field name of the event fields.
+func subjectField(fields map[string]any, name string) string {
+	if name == "status_class" {
+		if status, err := strconv.Atoi(subjectField(fields, "status")); err == nil {
+			return strconv.Itoa(status/100) + "xx"
+		}
+		return ""
+	}
+	switch v := fields[name].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// subjectToken turns v into a single NATS subject token: the leading and
+// trailing slashes are removed, the inner ones become token separators and
+// wildcards, dots, colons and whitespace are replaced with underscores.
+func subjectToken(v string) string {
+	v = strings.Trim(v, "/")
+	if v == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/':
+			return '.'
+		case '.', '*', '>', ':', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, v)
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNATSWriter(t *testing.T) {
+	var subjects []string
+	var data [][]byte
+	pub := NATSPublisherFunc(func(subject string, d []byte) error {
+		subjects = append(subjects, subject)
+		data = append(data, d)
+		return nil
+	})
+
+	w := NewNATSWriter(pub, "access.{status_class}.{method}.{route}")
+	_, err := w.Write([]byte(`{"status":503,"method":"GET","route":"/users/:id"}` + "\n"))
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(`{"status":200,"method":"GET"}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"access.5xx.GET.users._id", "access.2xx.GET._"}, subjects)
+	assert.Equal(t, `{"status":200,"method":"GET"}`, string(data[1]))
+
+	_, err = w.Write([]byte("GET /example 200\n"))
+	assert.Error(t, err)
+
+	// A subject without fields does not decode events.
+	subjects = nil
+	w = NewNATSWriter(pub, "access")
+	_, err = w.Write([]byte("GET /example 200\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"access"}, subjects)
+}
+
+func TestLoggerNATSWriter(t *testing.T) {
+	var subjects []string
+	w := NewNATSWriter(NATSPublisherFunc(func(subject string, _ []byte) error {
+		subjects = append(subjects, subject)
+		return nil
+	}), "access.{status_class}.{route}")
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(w),
+		WithAutoFormat(),
+	))
+	r.GET("/users/:id", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/users/42")
+	assert.Equal(t, []string{"access.2xx.users._id"}, subjects)
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+)
+
+// networkPublic is the class of client IPs matching no configured network.
+const networkPublic = "public"
+
+// networkRange is a client network and its class.
+type networkRange struct {
+	prefix netip.Prefix
+	class  string
+}
+
+// networkClassifier classifies client IPs by the most specific configured network.
+type networkClassifier []networkRange
+
+// newNetworkClassifier parses cidrs, a map of CIDR to class. It panics if a CIDR is invalid.
+func newNetworkClassifier(cidrs map[string]string) networkClassifier {
+	nc := make(networkClassifier, 0, len(cidrs))
+	for cidr, class := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("logger: invalid network %q: %v", cidr, err))
+		}
+		nc = append(nc, networkRange{prefix: prefix.Masked(), class: class})
+	}
+	sort.Slice(nc, func(i, j int) bool {
+		return nc[i].prefix.Bits() > nc[j].prefix.Bits()
+	})
+	return nc
+}
+
+// classify returns the class of the client IP ip.
+func (nc networkClassifier) classify(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return networkPublic
+	}
+	addr = addr.Unmap()
+	for _, r := range nc {
+		if r.prefix.Contains(addr) {
+			return r.class
+		}
+	}
+	return networkPublic
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkClassifier(t *testing.T) {
+	nc := newNetworkClassifier(map[string]string{
+		"10.0.0.0/8":    "internal",
+		"10.8.0.0/16":   "vpn",
+		"fd00::/8":      "internal",
+		"192.168.1.7/8": "lab",
+	})
+	assert.Equal(t, "internal", nc.classify("10.1.2.3"))
+	assert.Equal(t, "vpn", nc.classify("10.8.2.3"))
+	assert.Equal(t, "internal", nc.classify("fd12::1"))
+	assert.Equal(t, "internal", nc.classify("::ffff:10.1.2.3"))
+	assert.Equal(t, "lab", nc.classify("192.1.1.1"))
+	assert.Equal(t, "public", nc.classify("203.0.113.9"))
+	assert.Equal(t, "public", nc.classify("not an ip"))
+
+	assert.Panics(t, func() { WithNetworkClassifier(map[string]string{"10.0.0.0": "internal"}) })
+}
+
+func TestLoggerNetworkClassifier(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithNetworkClassifier(map[string]string{"192.0.2.0/24": "internal"})))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "network=internal")
+}
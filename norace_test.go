@@ -0,0 +1,6 @@
+//go:build !race
+
+package logger
+
+// raceEnabled reports whether the race detector, which allocates on its own, is on.
+const raceEnabled = false
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	numericSegment = regexp.MustCompile(`^[0-9]+$`)
+	localeSegment  = regexp.MustCompile(`^[a-z]{2}(-[A-Za-z]{2})?$`)
+)
+
+// NormalizeIDs is a path normalizer for WithPathNormalizer that replaces UUID
+// and numeric path segments with ":uuid" and ":id", so /users/42 becomes /users/:id.
+func NormalizeIDs(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		switch {
+		case uuidSegment.MatchString(s):
+			segments[i] = ":uuid"
+		case numericSegment.MatchString(s):
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// StripLocale is a path normalizer for WithPathNormalizer that removes a leading
+// locale segment such as /en or /pt-BR from the path.
+func StripLocale(path string) string {
+	rest := strings.TrimPrefix(path, "/")
+	segment, tail, _ := strings.Cut(rest, "/")
+	if !localeSegment.MatchString(segment) {
+		return path
+	}
+	return "/" + tail
+}
+
+// ChainNormalizers returns a path normalizer applying fns in order.
+func ChainNormalizers(fns ...func(string) string) func(string) string {
+	return func(path string) string {
+		for _, fn := range fns {
+			path = fn(path)
+		}
+		return path
+	}
+}
+
+// truncatePath returns p cut to at most n bytes, without splitting a UTF-8
+// sequence, followed by a marker with the number of bytes removed.
+func truncatePath(p string, n int) string {
+	if len(p) <= n {
+		return p
+	}
+	cut := n
+	for cut > 0 && !utf8.RuneStart(p[cut]) {
+		cut--
+	}
+	return p[:cut] + "...+" + strconv.Itoa(len(p)-cut) + " bytes"
+}
+
+// decodedPath returns the percent-decoded path of u in Unicode normalization form C,
+// so a path matches whether the client sent composed or decomposed characters.
+func decodedPath(u *url.URL) string {
+	return norm.NFC.String(u.Path)
+}
+
+// rawPath returns the path of the request target exactly as the client sent it.
+func rawPath(r *http.Request) string {
+	if r.RequestURI == "" {
+		return r.URL.EscapedPath()
+	}
+	p, _, _ := strings.Cut(r.RequestURI, "?")
+	return p
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizers(t *testing.T) {
+	assert.Equal(t, "/users/:id/orders/:uuid", NormalizeIDs("/users/42/orders/0b6e1c8a-9f0e-4d6a-8f65-1c2b3d4e5f60"))
+	assert.Equal(t, "/users/me", NormalizeIDs("/users/me"))
+	assert.Equal(t, "/docs/intro", StripLocale("/pt-BR/docs/intro"))
+	assert.Equal(t, "/", StripLocale("/en"))
+	assert.Equal(t, "/docs/intro", StripLocale("/docs/intro"))
+	assert.Equal(t, "/users/:id", ChainNormalizers(StripLocale, NormalizeIDs)("/fr/users/7"))
+}
+
+func TestLoggerPathNormalizer(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithPathNormalizer(NormalizeIDs),
+		WithSkipPath([]string{"/health/1"}),
+	))
+	r.GET("/users/*rest", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler")
+	})
+	r.GET("/health/:n", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/users/42?page=2")
+	assert.Contains(t, buffer.String(), "path=/users/:id?page=2")
+	assert.NotContains(t, buffer.String(), "/users/42")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/health/1")
+	assert.Empty(t, buffer.String())
+}
+
+func TestTruncatePath(t *testing.T) {
+	assert.Equal(t, "/short", truncatePath("/short", 10))
+	assert.Equal(t, "/abc...+3 bytes", truncatePath("/abcdef", 4))
+	assert.Equal(t, "/...+4 bytes", truncatePath("/éé", 2))
+}
+
+func TestLoggerMaxPathLength(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithMaxPathLength(16)))
+	r.GET("/search", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/search?q="+strings.Repeat("x", 100))
+	assert.Contains(t, buffer.String(), `path="/search?q=xxxxxx...+94 bytes"`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/search?q=go")
+	assert.Contains(t, buffer.String(), "path=/search?q=go")
+}
+
+func TestLoggerDecodedPath(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithDecodedPath(true)))
+	r.GET("/menu/:item", func(c *gin.Context) {})
+
+	// "cafe" followed by a combining acute accent.
+	performRequest(r, "GET", "/menu/cafe%CC%81?size=l")
+	assert.Contains(t, buffer.String(), `decoded_path="/menu/café"`)
+	assert.Contains(t, buffer.String(), "raw_path=/menu/cafe%CC%81")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/menu/caf%C3%A9")
+	assert.Contains(t, buffer.String(), `decoded_path="/menu/café"`)
+	assert.Contains(t, buffer.String(), "raw_path=/menu/caf%C3%A9")
+}
+
+func TestLoggerSplitQuery(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithSplitQuery(true)))
+	r.GET("/search", func(c *gin.Context) {
+		l := Get(c)
+		l.Info().Msg("handler")
+	})
+
+	performRequest(r, "GET", "/search?q=go&page=2")
+	assert.Equal(t, 2, strings.Count(buffer.String(), "path=/search "))
+	assert.Equal(t, 2, strings.Count(buffer.String(), "query=q=go&page=2"))
+
+	buffer.Reset()
+	performRequest(r, "GET", "/search")
+	assert.NotContains(t, buffer.String(), "query=")
+}
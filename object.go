@@ -0,0 +1,311 @@
+package logger
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Suffixes of the files of the spool directory of an ObjectShipper.
+const (
+	objectSuffix = ".json.gz"
+	partSuffix   = ".part"
+)
+
+// ObjectUploader stores an object in S3-compatible storage. Implement it with
+// the client of your choice, e.g. with the AWS SDK:
+//
+//	logger.ObjectUploaderFunc(func(ctx context.Context, key string, body io.Reader) error {
+//		_, err := uploader.Upload(ctx, &s3.PutObjectInput{
+//			Bucket: aws.String("access-logs"),
+//			Key:    aws.String(key),
+//			Body:   body,
+//		})
+//		return err
+//	})
+type ObjectUploader interface {
+	Upload(ctx context.Context, key string, body io.Reader) error
+}
+
+// ObjectUploaderFunc is an adapter to allow the use of ordinary functions as ObjectUploader.
+type ObjectUploaderFunc func(ctx context.Context, key string, body io.Reader) error
+
+// Upload calls f(ctx, key, body).
+func (f ObjectUploaderFunc) Upload(ctx context.Context, key string, body io.Reader) error {
+	return f(ctx, key, body)
+}
+
+// Defaults of an ObjectShipper.
+const (
+	// defaultUploadTimeout bounds an upload when no timeout is given.
+	defaultUploadTimeout = time.Minute
+	// objectRetryDelay is the delay before objects that failed to upload are
+	// uploaded again.
+	objectRetryDelay = 30 * time.Second
+)
+
+// ObjectShipper rolls events into gzip-compressed newline-delimited JSON objects
+// and uploads them to object storage, for cheap archival of access logs. Events
+// are compressed into a file of a local spool directory, which is rolled and
+// uploaded once maxBytes bytes of events were written to it, or flushInterval
+// after its first event. Objects that fail to upload stay in the spool directory
+// and are retried 30 seconds later, and on the next upload, oldest first, so an
+// outage of the storage loses no event. Objects are named after the time they
+// were started, under a key prefix, e.g.
+// "access/20240102T030405.000000000Z-1.json.gz".
+//
+// The spool files are named after the key prefix, so shippers with different
+// prefixes can share a spool directory: each only uploads and recovers its own
+// files. Shippers with the same prefix cannot, since their objects would get the
+// same keys; NewObjectShipper fails for a second one in the same process.
+//
+// Uploads run without blocking the writes of other goroutines, each bounded by
+// an upload timeout. The upload of an object rolled because it reached maxBytes
+// still happens in the Write call rolling it: wrap the shipper in an
+// AsyncWriter, or use WithAsync, to keep the storage off the request path. Call
+// Close during shutdown to upload the last object.
+type ObjectShipper struct {
+	up       ObjectUploader
+	prefix   string
+	spool    string
+	maxBytes int64
+	interval time.Duration
+	timeout  time.Duration
+	retry    time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	file       *os.File
+	gz         *gzip.Writer
+	size       int64
+	seq        int
+	timer      *time.Timer
+	retryTimer *time.Timer
+	closed     bool
+	release    sync.Once
+
+	// uploadMu serializes uploads, so an object is never uploaded twice.
+	uploadMu sync.Mutex
+}
+
+// spoolsMu guards spools, the spools of the open shippers of the process.
+var (
+	spoolsMu sync.Mutex
+	spools   = map[string]struct{}{}
+)
+
+// spoolName returns the prefix of the spool file names of the shippers
+// uploading under the key prefix.
+func spoolName(prefix string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(prefix))
+	return fmt.Sprintf("%016x-", h.Sum64())
+}
+
+// NewObjectShipper returns an ObjectShipper spooling objects in dir, which it
+// creates if needed, and uploading them through up under the key prefix, each
+// upload cancelled after uploadTimeout, or a minute when it is not positive.
+// Zero or negative limits disable the corresponding trigger. Objects left in dir
+// by a previous process with the same prefix are uploaded with the first
+// object; one that was still being written may lack its gzip trailer.
+func NewObjectShipper(up ObjectUploader, dir, prefix string, maxBytes int64, flushInterval, uploadTimeout time.Duration) (*ObjectShipper, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	spool := filepath.Join(abs, spoolName(prefix))
+	spoolsMu.Lock()
+	defer spoolsMu.Unlock()
+	if _, ok := spools[spool]; ok {
+		return nil, fmt.Errorf("logger: an object shipper with the prefix %q already spools in %s", prefix, dir)
+	}
+
+	parts, err := filepath.Glob(spool + "*" + objectSuffix + partSuffix)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range parts {
+		if err := os.Rename(part, strings.TrimSuffix(part, partSuffix)); err != nil {
+			return nil, err
+		}
+	}
+	if uploadTimeout <= 0 {
+		uploadTimeout = defaultUploadTimeout
+	}
+	spools[spool] = struct{}{}
+	return &ObjectShipper{
+		up:       up,
+		prefix:   prefix,
+		spool:    spool,
+		maxBytes: maxBytes,
+		interval: flushInterval,
+		timeout:  uploadTimeout,
+		retry:    objectRetryDelay,
+		now:      time.Now,
+	}, nil
+}
+
+// Write adds the event p to the current object. Errors of the spool file are
+// returned, and ErrWriterClosed once the shipper is closed. Upload errors are
+// not, since the event is kept in the spool directory: they are returned by
+// Flush and Close.
+func (s *ObjectShipper) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrWriterClosed
+	}
+	if s.gz == nil {
+		s.seq++
+		name := s.now().UTC().Format("20060102T150405.000000000Z") + "-" + strconv.Itoa(s.seq) + objectSuffix + partSuffix
+		f, err := os.OpenFile(s.spool+name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err != nil {
+			s.mu.Unlock()
+			return 0, err
+		}
+		s.file, s.gz, s.size = f, gzip.NewWriter(f), 0
+		if s.interval > 0 {
+			s.timer = time.AfterFunc(s.interval, func() { _ = s.Flush() })
+		}
+	}
+	if _, err := s.gz.Write(p); err != nil {
+		s.mu.Unlock()
+		return 0, err
+	}
+	s.size += int64(len(p))
+	var err error
+	full := s.maxBytes > 0 && s.size >= s.maxBytes
+	if full {
+		err = s.rollLocked()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+	if full {
+		_ = s.upload()
+	}
+	return len(p), nil
+}
+
+// Flush rolls the current object and uploads the spooled objects.
+func (s *ObjectShipper) Flush() error {
+	s.mu.Lock()
+	err := s.rollLocked()
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.upload()
+}
+
+// Close rolls the current object, uploads the spooled objects and stops the
+// retries of the objects that failed to upload, which stay in the spool
+// directory for the next process. Calling Close again uploads them again.
+func (s *ObjectShipper) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	if s.retryTimer != nil {
+		s.retryTimer.Stop()
+		s.retryTimer = nil
+	}
+	err := s.rollLocked()
+	s.mu.Unlock()
+	s.release.Do(func() {
+		spoolsMu.Lock()
+		defer spoolsMu.Unlock()
+		delete(spools, s.spool)
+	})
+	if err != nil {
+		return err
+	}
+	return s.upload()
+}
+
+// Pending returns the number of rolled objects waiting to be uploaded.
+func (s *ObjectShipper) Pending() int {
+	objects, _ := filepath.Glob(s.spool + "*" + objectSuffix)
+	return len(objects)
+}
+
+// rollLocked completes the current object, if any, and makes it ready for upload.
+func (s *ObjectShipper) rollLocked() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.gz == nil {
+		return nil
+	}
+	f, gz := s.file, s.gz
+	s.file, s.gz = nil, nil
+	if err := errors.Join(gz.Close(), f.Close()); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), strings.TrimSuffix(f.Name(), partSuffix))
+}
+
+// upload uploads the spooled objects, oldest first, and schedules a retry when
+// one fails.
+func (s *ObjectShipper) upload() error {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+
+	objects, err := filepath.Glob(s.spool + "*" + objectSuffix)
+	if err != nil {
+		return err
+	}
+	sort.Strings(objects)
+	for _, path := range objects {
+		if err := s.uploadObject(path); err != nil {
+			s.scheduleRetry()
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduleRetry uploads the spooled objects again after the retry delay, unless
+// a retry is already scheduled or the shipper is closed.
+func (s *ObjectShipper) scheduleRetry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed || s.retryTimer != nil {
+		return
+	}
+	s.retryTimer = time.AfterFunc(s.retry, func() {
+		s.mu.Lock()
+		s.retryTimer = nil
+		s.mu.Unlock()
+		_ = s.upload()
+	})
+}
+
+// uploadObject uploads the spooled object at path and removes it.
+func (s *ObjectShipper) uploadObject(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	err = s.up.Upload(ctx, s.prefix+strings.TrimPrefix(path, s.spool), f)
+	if err := errors.Join(err, f.Close()); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// objectStore keeps the uploaded objects decompressed, failing while down is true.
+type objectStore struct {
+	mu      sync.Mutex
+	down    bool
+	keys    []string
+	objects []string
+}
+
+func (s *objectStore) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *objectStore) Upload(_ context.Context, key string, body io.Reader) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return errors.New("service unavailable")
+	}
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return err
+	}
+	b, err := io.ReadAll(gz)
+	if err != nil {
+		return err
+	}
+	s.keys = append(s.keys, key)
+	s.objects = append(s.objects, string(b))
+	return nil
+}
+
+func TestObjectShipper(t *testing.T) {
+	store := &objectStore{}
+	dir := t.TempDir()
+	s, err := NewObjectShipper(store, dir, "access/", 16, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	_, err = s.Write([]byte(`{"a":1}` + "\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, store.keys)
+	_, err = s.Write([]byte(`{"b":2}` + "\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"access/20240102T030405.000000000Z-1.json.gz"}, store.keys)
+	assert.Equal(t, []string{`{"a":1}` + "\n" + `{"b":2}` + "\n"}, store.objects)
+
+	// Objects failing to upload are spooled and retried in order.
+	store.setDown(true)
+	_, err = s.Write([]byte(`{"c":3}` + "\n"))
+	assert.NoError(t, err)
+	assert.Error(t, s.Flush())
+	_, err = s.Write([]byte(`{"d":4}` + "\n"))
+	assert.NoError(t, err)
+	assert.Error(t, s.Close())
+	assert.Equal(t, 2, s.Pending())
+
+	store.setDown(false)
+	assert.NoError(t, s.Close())
+	assert.Equal(t, 0, s.Pending())
+	assert.Equal(t, []string{`{"c":3}` + "\n", `{"d":4}` + "\n"}, store.objects[1:])
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestObjectShipperInterval(t *testing.T) {
+	uploaded := make(chan string, 1)
+	s, err := NewObjectShipper(ObjectUploaderFunc(func(_ context.Context, key string, _ io.Reader) error {
+		uploaded <- key
+		return nil
+	}), t.TempDir(), "", 0, 10*time.Millisecond, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.Write([]byte(`{"a":1}` + "\n"))
+	assert.NoError(t, err)
+	select {
+	case key := <-uploaded:
+		assert.True(t, strings.HasSuffix(key, ".json.gz"))
+	case <-time.After(time.Second):
+		t.Fatal("object not uploaded after the flush interval")
+	}
+}
+
+func TestObjectShipperLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, spoolName("access/")+"old.json.gz.part"), nil, 0o600))
+	// The files of a shipper with another prefix are left alone, even when
+	// it is still writing them.
+	other := filepath.Join(dir, spoolName("audit/")+"live.json.gz.part")
+	assert.NoError(t, os.WriteFile(other, nil, 0o600))
+	var keys []string
+	s, err := NewObjectShipper(ObjectUploaderFunc(func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	}), dir, "access/", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, s.Pending())
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, []string{"access/old.json.gz"}, keys)
+	_, err = os.Stat(other)
+	assert.NoError(t, err)
+}
+
+func TestObjectShipperSharedDir(t *testing.T) {
+	dir := t.TempDir()
+	access := &objectStore{}
+	a, err := NewObjectShipper(access, dir, "access/", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit := &objectStore{}
+	b, err := NewObjectShipper(audit, dir, "audit/", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewObjectShipper(access, dir, "access/", 0, 0, 0)
+	assert.Error(t, err)
+
+	_, err = a.Write([]byte(`{"a":1}` + "\n"))
+	assert.NoError(t, err)
+	_, err = b.Write([]byte(`{"b":2}` + "\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, a.Close())
+	assert.Equal(t, []string{`{"a":1}` + "\n"}, access.objects)
+	assert.Empty(t, audit.objects)
+	assert.NoError(t, b.Close())
+	assert.Equal(t, []string{`{"b":2}` + "\n"}, audit.objects)
+
+	// Closing a shipper frees its prefix.
+	a, err = NewObjectShipper(access, dir, "access/", 0, 0, 0)
+	if assert.NoError(t, err) {
+		assert.NoError(t, a.Close())
+	}
+}
+
+func TestObjectShipperWriteAfterClose(t *testing.T) {
+	s, err := NewObjectShipper(&objectStore{}, t.TempDir(), "", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, s.Close())
+	_, err = s.Write([]byte(`{"a":1}` + "\n"))
+	assert.ErrorIs(t, err, ErrWriterClosed)
+	assert.Equal(t, 0, s.Pending())
+}
+
+func TestObjectShipperRetry(t *testing.T) {
+	store := &objectStore{down: true}
+	s, err := NewObjectShipper(store, t.TempDir(), "", 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.retry = 10 * time.Millisecond
+	defer s.Close()
+
+	_, err = s.Write([]byte(`{"a":1}` + "\n"))
+	assert.NoError(t, err)
+	assert.Error(t, s.Flush())
+	assert.Equal(t, 1, s.Pending())
+
+	// The failed object is uploaded again without another roll.
+	store.setDown(false)
+	assert.Eventually(t, func() bool { return s.Pending() == 0 }, time.Second, time.Millisecond)
+}
+
+func TestObjectShipperUploadTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	s, err := NewObjectShipper(ObjectUploaderFunc(func(ctx context.Context, _ string, _ io.Reader) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	}), t.TempDir(), "", 0, 0, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.retry = time.Hour
+	defer s.Close()
+
+	_, err = s.Write([]byte(`{"a":1}` + "\n"))
+	assert.NoError(t, err)
+	flushed := make(chan error, 1)
+	go func() { flushed <- s.Flush() }()
+	<-started
+
+	// Writes do not wait for the hanging upload.
+	begin := time.Now()
+	_, err = s.Write([]byte(`{"b":2}` + "\n"))
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(begin), 50*time.Millisecond)
+
+	assert.ErrorIs(t, <-flushed, context.DeadlineExceeded)
+}
@@ -1,8 +1,14 @@
 package logger
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"os"
 	"regexp"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
@@ -25,7 +31,43 @@ func (o optionFunc) apply(c *config) {
 	o(c)
 }
 
+// Compose returns an Option applying opts in order, so a set of options can be
+// published as a single reusable bundle. Bundles can be nested and combined with
+// other options, later options overriding earlier ones.
+func Compose(opts ...Option) Option {
+	return optionFunc(func(c *config) {
+		for _, o := range opts {
+			o.apply(c)
+		}
+	})
+}
+
+// defaults holds the options set with SetGlobalDefaults.
+var defaults struct {
+	mu   sync.RWMutex
+	opts []Option
+}
+
+// SetGlobalDefaults sets options applied by every later SetLogger and New call in
+// the binary before their own options, so an organization's base configuration
+// (format, redaction, skip presets) is written once. Per-call options override them.
+// Calling it again replaces the previous defaults; calling it without options clears them.
+func SetGlobalDefaults(opts ...Option) {
+	defaults.mu.Lock()
+	defer defaults.mu.Unlock()
+	defaults.opts = append([]Option(nil), opts...)
+}
+
+// globalDefaults returns the options set with SetGlobalDefaults.
+func globalDefaults() []Option {
+	defaults.mu.RLock()
+	defer defaults.mu.RUnlock()
+	return defaults.opts
+}
+
 // WithLogger returns an Option that sets the logger function in the config.
+// A logger returned with another output, e.g. through Output, replaces the
+// writer set with WithWriter; New reports the combination with ErrOptionWarning.
 // The logger function is a function that takes a *gin.Context and a zerolog.Logger,
 // and returns a zerolog.Logger. This function is typically used to modify or enhance
 // the logger within the context of a Gin HTTP request.
@@ -63,6 +105,51 @@ func WithSkipPathRegexps(regs ...*regexp.Regexp) Option {
 	})
 }
 
+// WithSkipPathPrefix returns an Option that skips logging requests whose path
+// starts with one of prefixes, such as "/static/", without the cost of a regular
+// expression.
+func WithSkipPathPrefix(prefixes ...string) Option {
+	return optionFunc(func(c *config) {
+		c.skipPathPrefixes = append(c.skipPathPrefixes, prefixes...)
+	})
+}
+
+// WithSkipPathGlob returns an Option that skips logging requests whose path
+// matches one of the path.Match patterns, such as "/assets/*/*.js". A "*" does
+// not match across "/". SetLogger panics if a pattern is malformed.
+func WithSkipPathGlob(patterns ...string) Option {
+	return optionFunc(func(c *config) {
+		c.skipPathGlobs = append(c.skipPathGlobs, patterns...)
+	})
+}
+
+// WithOnlyPaths returns an Option that logs only the requests whose path is one
+// of paths, the inverse of WithSkipPath, to scope a logger attached to the engine
+// to a subset of routes. Combined with WithOnlyPathRegexps, a request is logged
+// when it matches either. Skip rules still apply to the requests selected.
+func WithOnlyPaths(paths ...string) Option {
+	return optionFunc(func(c *config) {
+		c.onlyPaths = append(c.onlyPaths, paths...)
+	})
+}
+
+// WithOnlyPathRegexps returns an Option that logs only the requests whose path
+// matches one of regs, such as ^/api/, the inverse of WithSkipPathRegexps.
+func WithOnlyPathRegexps(regs ...*regexp.Regexp) Option {
+	return optionFunc(func(c *config) {
+		c.onlyPathRegexps = append(c.onlyPathRegexps, regs...)
+	})
+}
+
+// WithMatcher returns an Option that decides which requests are logged with m,
+// built once with NewMatcher, in place of the skip and only rules of the options.
+// Sharing a Matcher saves each middleware instance from building its own.
+func WithMatcher(m *Matcher) Option {
+	return optionFunc(func(c *config) {
+		c.matcher = m
+	})
+}
+
 // WithUTC returns an Option that sets the utc field in the config.
 // The utc field is a boolean that indicates whether to use UTC time zone or local time zone.
 //
@@ -86,6 +173,15 @@ func WithSkipPath(s []string) Option {
 	})
 }
 
+// WithPostSkipper returns an Option that sets a PostSkipper, evaluated after
+// c.Next() so the decision can depend on the outcome, e.g. skipping fast
+// successful requests while always logging errors.
+func WithPostSkipper(s PostSkipper) Option {
+	return optionFunc(func(c *config) {
+		c.postSkip = s
+	})
+}
+
 // WithPathLevel use logging level for successful requests to a specific path
 func WithPathLevel(m map[string]zerolog.Level) Option {
 	return optionFunc(func(c *config) {
@@ -101,6 +197,17 @@ func WithWriter(s io.Writer) Option {
 	})
 }
 
+// WithLevelFromHeader returns an Option that lets callers accepted by trusted,
+// e.g. TrustCIDRs or TrustSecret, raise the verbosity of a single request with a
+// header such as "X-Log-Level: debug". The level applies to the logger returned by
+// Get; it can only make logging more verbose and zerolog's global level still applies.
+func WithLevelFromHeader(header string, trusted Trust) Option {
+	return optionFunc(func(c *config) {
+		c.levelHeader = header
+		c.levelHeaderTrust = trusted
+	})
+}
+
 // WithDefaultLevel set the log level used for request with status code < 400
 func WithDefaultLevel(lvl zerolog.Level) Option {
 	return optionFunc(func(c *config) {
@@ -157,3 +264,779 @@ func WithContext(fn func(*gin.Context, *zerolog.Event) *zerolog.Event) Option {
 		c.context = fn
 	})
 }
+
+// WithCompressionFields returns an Option that logs the response Content-Encoding,
+// the compressed and uncompressed body sizes when a compression middleware such as
+// gin-contrib/gzip is registered after the logger, and whether a 304 Not Modified
+// response was the result of an ETag match.
+func WithCompressionFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.compression = s
+	})
+}
+
+// WithRangeFields returns an Option that logs the requested range, the served
+// Content-Range and the bytes served when the request carries a Range header or
+// the response status is 206 Partial Content.
+func WithRangeFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.ranges = s
+	})
+}
+
+// WithConditionalFields returns an Option that logs, for requests carrying
+// If-None-Match or If-Modified-Since, a "conditional" group with the headers
+// present, whether the response was 304 Not Modified and whether the ETag matched,
+// to help tune caching from access logs.
+func WithConditionalFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.conditional = s
+	})
+}
+
+// WithEnvoyFields returns an Option that logs the x-envoy-* headers added by an
+// Envoy or Istio sidecar, such as the attempt count, the upstream service time and
+// the external address, as an "envoy" group for the request headers and an
+// "envoy_response" group for the response headers, so retries and sidecar
+// latency are visible in application access logs.
+func WithEnvoyFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.envoy = s
+	})
+}
+
+// WithQueueTime returns an Option that logs "queue_time", the time between the
+// X-Request-Start or X-Queue-Start timestamp set by an upstream proxy and the
+// request reaching the middleware, exposing how long requests waited before
+// reaching the application. Timestamps in the future, from clock skew, are ignored.
+func WithQueueTime(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.queueTime = s
+	})
+}
+
+// WithTrailers returns an Option that logs the given HTTP trailers, such as
+// grpc-status for proxied grpc-web traffic, from both the request and the response.
+// Trailers are read after the response body has been fully written.
+func WithTrailers(names ...string) Option {
+	return optionFunc(func(c *config) {
+		c.trailers = append(c.trailers, names...)
+	})
+}
+
+// WithBodyFields returns an Option that logs selected values of JSON request bodies
+// instead of the whole payload. Paths are dot separated object keys or array indexes,
+// e.g. "user.id" or "items.0.sku", and matched values are logged under "body".
+// Bodies larger than 1 MiB are not inspected.
+func WithBodyFields(paths ...string) Option {
+	return optionFunc(func(c *config) {
+		c.bodyFields = append(c.bodyFields, paths...)
+	})
+}
+
+// WithBodyHash returns an Option that logs a digest of the request body as
+// "body_hash", and of the response body as "response_body_hash" when response
+// is true, without logging the content itself. The request body is hashed as the
+// handler reads it; up to 1 MiB left unread is drained to complete the digest.
+func WithBodyHash(alg HashAlgorithm, response bool) Option {
+	return optionFunc(func(c *config) {
+		c.bodyHash = &alg
+		c.responseBodyHash = response
+	})
+}
+
+// WithRedactors returns an Option that replaces the redactors applied to captured
+// bodies and headers. Without this option DefaultRedactors are used; to extend them,
+// pass append(DefaultRedactors(), custom...). Calling it without arguments disables redaction.
+func WithRedactors(r ...Redactor) Option {
+	return optionFunc(func(c *config) {
+		c.redactors = append([]Redactor{}, r...)
+	})
+}
+
+// WithAuthUser returns an Option that logs the username of an Authorization: Basic
+// header as "auth_user". The password is never logged, and Authorization headers
+// are always reduced to their scheme wherever headers are logged.
+func WithAuthUser(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.authUser = s
+	})
+}
+
+// WithSession returns an Option that logs the session identifier returned by fn
+// as "session_id", so a user journey can be reconstructed across requests.
+// Empty identifiers are not logged.
+func WithSession(fn func(*gin.Context) string) Option {
+	return optionFunc(func(c *config) {
+		c.session = fn
+	})
+}
+
+// WithSessionCookie returns an Option that logs the value of the named session
+// cookie as "session_id". When hashed is true the hex encoded SHA-256 of the value
+// is logged instead, which still correlates requests without exposing the session.
+func WithSessionCookie(name string, hashed bool) Option {
+	return WithSession(func(c *gin.Context) string {
+		id, err := c.Cookie(name)
+		if err != nil || id == "" {
+			return ""
+		}
+		if hashed {
+			sum := sha256.Sum256([]byte(id))
+			return hex.EncodeToString(sum[:])
+		}
+		return id
+	})
+}
+
+// WithIdempotencyKey returns an Option that logs the value of the named request
+// header, e.g. "Idempotency-Key", as "idempotency_key" so retries of a request can
+// be joined across log lines. When hashed is true the hex encoded SHA-256 of the
+// key is logged instead.
+func WithIdempotencyKey(header string, hashed bool) Option {
+	return optionFunc(func(c *config) {
+		c.extraFields = append(c.extraFields, func(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+			key := c.GetHeader(header)
+			if key == "" {
+				return evt
+			}
+			if hashed {
+				sum := sha256.Sum256([]byte(key))
+				key = hex.EncodeToString(sum[:])
+			}
+			return evt.Str("idempotency_key", key)
+		})
+	})
+}
+
+// WithRateLimitFields returns an Option that logs the limit, remaining, reset and
+// Retry-After values set by rate limiting middleware as a "ratelimit" group, along
+// with whether the request was rejected with 429 Too Many Requests.
+func WithRateLimitFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.rateLimit = s
+	})
+}
+
+// WithCORSFields returns an Option that logs, for requests carrying an Origin header,
+// the origin, whether the request is a CORS preflight and whether the response's
+// Access-Control-Allow-Origin header allowed it. This surfaces cross-origin failures
+// that otherwise only show up as errors in the browser console.
+func WithCORSFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.cors = s
+	})
+}
+
+// WithNegotiationFields returns an Option that logs the requested Accept-Language
+// and Accept-Encoding alongside the Content-Language and Content-Encoding served,
+// as a "negotiation" group, so localization coverage can be measured from access logs.
+func WithNegotiationFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.negotiation = s
+	})
+}
+
+// WithTrafficClass returns an Option that labels every event, including those
+// written through Get(c), with the "traffic_class" returned by fn (e.g. "interactive",
+// "batch" or "internal"), so SLOs can be computed per class from the logs.
+// fn runs before the handler; empty labels are not logged.
+func WithTrafficClass(fn func(*gin.Context) string) Option {
+	return optionFunc(func(c *config) {
+		c.trafficClass = fn
+	})
+}
+
+// WithSLO returns an Option that checks every request against the SLO of its
+// route template (c.FullPath(), or the URL path for unmatched requests).
+// Requests breaching their objective are logged with "slo_breach": true and at
+// least at warn level, and the periodic summary enabled by WithSummaryInterval
+// reports the budget burn rate of each route.
+func WithSLO(slos map[string]SLO) Option {
+	return optionFunc(func(c *config) {
+		c.slos = slos
+	})
+}
+
+// WithSummaryInterval returns an Option that writes a "Summary" event with the
+// aggregated measurements of the middleware, such as SLO budget burn, at most once
+// per interval. Summaries are written by the first request completing after the
+// interval elapsed, so no background goroutine is started.
+func WithSummaryInterval(d time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.summaryInterval = d
+	})
+}
+
+// WithMetrics returns an Option that passes the latency of every request to m,
+// including requests skipped or sampled out, and adds the metric series labels to
+// the event as "metric_labels". Requests not matching a route are labeled
+// "<unmatched>", unless WithPathNormalizer is set. Combined with WithTraceID,
+// observations carry the trace ID as an exemplar, linking metrics, traces and log
+// lines.
+func WithMetrics(m MetricsObserver) Option {
+	return optionFunc(func(c *config) {
+		c.metrics = m
+	})
+}
+
+// WithTraceID returns an Option that logs the trace identifier returned by fn,
+// for instance from an OpenTelemetry span in the request context, as "trace_id".
+// Empty identifiers are not logged.
+func WithTraceID(fn func(*gin.Context) string) Option {
+	return optionFunc(func(c *config) {
+		c.traceID = fn
+	})
+}
+
+// WithExpvar returns an Option that publishes the middleware's own counters via
+// expvar: requests_total, errors_total (responses with status >= 500),
+// dropped_log_events (events the output writer failed to write) and bytes_written.
+// They are published as a map named "gin_logger" unless WithExpvarPrefix is used.
+// Only writes to the writer set by WithWriter are counted.
+func WithExpvar(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.expvar = s
+	})
+}
+
+// WithExpvarPrefix returns an Option that sets the name of the expvar map used by
+// WithExpvar. Instances sharing a prefix share their counters.
+func WithExpvarPrefix(prefix string) Option {
+	return optionFunc(func(c *config) {
+		c.expvarPrefix = prefix
+	})
+}
+
+// WithBatching returns an Option that buffers events and writes them to the output
+// in batches of up to maxEvents events or maxBytes bytes, at the latest flushInterval
+// after the first buffered event. Use Flush during shutdown to write pending events,
+// or NewPipeline to build a middleware whose Close writes and releases the batch.
+func WithBatching(maxEvents, maxBytes int, flushInterval time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.batch = &batchConfig{maxEvents: maxEvents, maxBytes: maxBytes, interval: flushInterval}
+	})
+}
+
+// WithAsync returns an Option that writes events from a background goroutine
+// through a queue of queueSize events, keeping formatting and I/O off the request
+// path. What happens when the queue is full is set by WithBackpressurePolicy.
+// Use Flush during shutdown to write pending events.
+func WithAsync(queueSize int) Option {
+	return optionFunc(func(c *config) {
+		c.asyncQueue = queueSize
+	})
+}
+
+// WithBackpressurePolicy returns an Option that sets the policy applied when the
+// queue enabled by WithAsync is full. Default is BackpressureBlock. The counters
+// of each policy are reported by Health.
+func WithBackpressurePolicy(p BackpressurePolicy) Option {
+	return optionFunc(func(c *config) {
+		c.backpressure = p
+	})
+}
+
+// WithGuaranteedErrorDelivery returns an Option that writes warn and higher events
+// synchronously to w, bypassing the queue of WithAsync and the batches of
+// WithBatching, so error lines survive a crash even when info lines are buffered.
+// Those events are only written to w.
+func WithGuaranteedErrorDelivery(w io.Writer) Option {
+	return optionFunc(func(c *config) {
+		c.errorOutput = w
+	})
+}
+
+// WithCrashDump returns an Option that keeps the last size events in memory and
+// appends them to the file at path when a handler panics, before the panic is
+// propagated, or when one of sigs (e.g. syscall.SIGTERM) is received, before the
+// process terminates. The events are kept before any queue or batch, so the
+// requests leading to a crash are preserved even if they were never written.
+//
+// Applications that handle the signals themselves should not pass them here
+// and call DumpCrash from their own handler instead.
+func WithCrashDump(path string, size int, sigs ...os.Signal) Option {
+	return optionFunc(func(c *config) {
+		c.crashPath = path
+		c.crashSize = size
+		c.crashSignals = sigs
+	})
+}
+
+// WithClock returns an Option that reads the current time from clock to measure
+// latency and timestamp events, so tests can assert exact values. The default
+// clock uses time.Now, whose monotonic reading keeps latencies correct across
+// wall clock adjustments.
+func WithClock(clock Clock) Option {
+	return optionFunc(func(c *config) {
+		c.clock = clock
+	})
+}
+
+// WithSampler returns an Option that only logs the successful requests kept by s.
+// Requests with a status code >= 400 are always logged. Use HashSampler for
+// sampling consistent across services, or RandomSampler with a fixed seed for
+// reproducible tests.
+func WithSampler(s Sampler) Option {
+	return optionFunc(func(c *config) {
+		c.sampler = s
+	})
+}
+
+// WithRouteParams returns an Option that logs the route parameters (c.Params) as a
+// "params" group, e.g. {"id": "42"} for /users/:id. When allow is not empty, only
+// the listed parameters are logged.
+func WithRouteParams(s bool, allow ...string) Option {
+	return optionFunc(func(c *config) {
+		c.routeParams = s
+		c.routeParamsAllow = make(map[string]struct{}, len(allow))
+		for _, key := range allow {
+			c.routeParamsAllow[key] = struct{}{}
+		}
+	})
+}
+
+// WithPathNormalizer returns an Option that rewrites the logged path with fn, e.g.
+// NormalizeIDs, to keep log and metric cardinality under control for services that
+// do not use route templates. The query string is not passed to fn and skip rules
+// still match the original path.
+func WithPathNormalizer(fn func(string) string) Option {
+	return optionFunc(func(c *config) {
+		c.pathNormalizer = fn
+	})
+}
+
+// WithDevFormat returns an Option that switches the console output to a multi-line
+// format for local development: the usual primary line followed by indented,
+// pretty-printed blocks for errors, captured bodies, headers and any other
+// structured field.
+func WithDevFormat() Option {
+	return optionFunc(func(c *config) {
+		c.devFormat = true
+	})
+}
+
+// WithConsoleColors returns an Option that colors console lines by status class,
+// latency and level, e.g. DefaultConsoleColors(). Colors are only used when
+// stdout is a terminal.
+func WithConsoleColors(colors *ConsoleColors) Option {
+	return optionFunc(func(c *config) {
+		c.consoleColors = colors
+	})
+}
+
+// WithAutoFormat returns an Option that writes human readable console lines when
+// the configured writer is a terminal and JSON otherwise, e.g. when it is a file
+// or a pipe. The check is made against the writer itself rather than stdout.
+func WithAutoFormat() Option {
+	return optionFunc(func(c *config) {
+		c.autoFormat = true
+	})
+}
+
+// WithFormatter returns an Option that renders every event with fn instead of
+// the console format, while keeping the skip, sampling and field collection of
+// the middleware. fn receives the computed values of the event as LogParams.
+func WithFormatter(fn Formatter) Option {
+	return optionFunc(func(c *config) {
+		c.formatter = fn
+	})
+}
+
+// WithGinFormat returns an Option that writes access lines in the exact default
+// format of gin.Logger(), colored under the same conditions, to ease migrating
+// from it. Like gin.Logger(), it only writes access lines, with the private
+// errors of the request. See GinFormatter.
+func WithGinFormat() Option {
+	return optionFunc(func(c *config) {
+		c.ginFormat = true
+	})
+}
+
+// WithRouteStats returns an Option that counts requests, errors, cumulative
+// latency and requests in flight per route, including skipped requests, for
+// Stats and StatsHandler.
+func WithRouteStats(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.routeStats = s
+	})
+}
+
+// WithLatencyBuckets returns an Option that adds a "latency_bucket" field such as
+// "10ms-100ms" to the access line, so log-only stacks can build latency
+// distributions with term aggregations. Without bounds, 10ms, 100ms and 1s are used.
+func WithLatencyBuckets(bounds ...time.Duration) Option {
+	if len(bounds) == 0 {
+		bounds = []time.Duration{10 * time.Millisecond, 100 * time.Millisecond, time.Second}
+	}
+	return optionFunc(func(c *config) {
+		c.latencyBuckets = newLatencyBuckets(bounds)
+	})
+}
+
+// WithApdex returns an Option that classifies every request as "satisfied",
+// "tolerating" or "frustrated" against the threshold in an "apdex" field, and adds
+// the aggregate Apdex score to the summary written with WithSummaryInterval.
+func WithApdex(threshold time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.apdexThreshold = threshold
+	})
+}
+
+// WithThroughput returns an Option that logs the effective request read and
+// response write rates in bytes per second as "request_throughput" and
+// "response_throughput", for transfers of at least minBytes, to tell slow clients
+// from slow servers on large transfers.
+func WithThroughput(minBytes int64) Option {
+	return optionFunc(func(c *config) {
+		c.throughput = minBytes
+	})
+}
+
+// WithTimingSplit returns an Option that splits the latency into "write_time",
+// the time spent writing and flushing the response, and "handler_time", the rest,
+// so slow serialization or network writes can be told from slow business logic.
+func WithTimingSplit() Option {
+	return optionFunc(func(c *config) {
+		c.timingSplit = true
+	})
+}
+
+// WithResolveClientHost returns an Option that logs the reverse DNS name of the
+// client IP as "client_host". Lookups run in the background and their results are
+// cached for cacheTTL in a bounded cache, so a host is logged from the first request
+// completing after its lookup finished and requests never wait on DNS.
+func WithResolveClientHost(s bool, cacheTTL time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.resolveHost = s
+		c.resolveHostTTL = cacheTTL
+	})
+}
+
+// WithNetworkClassifier returns an Option that adds a "network" field classifying
+// the client IP by cidrs, a map of CIDR to class such as
+//
+//	{"10.0.0.0/8": "internal", "100.64.0.0/10": "vpn"}
+//
+// The most specific matching network wins and other IPs are "public". It panics
+// if a CIDR is invalid.
+func WithNetworkClassifier(cidrs map[string]string) Option {
+	nc := newNetworkClassifier(cidrs)
+	return optionFunc(func(c *config) {
+		c.networks = nc
+	})
+}
+
+// WithGRPCStatus returns an Option that, for responses carrying a grpc-status
+// trailer or header (e.g. behind a gRPC gateway or grpc-web), logs the status as a
+// "grpc_code" field such as "UNAVAILABLE" and raises the level to the client or
+// server error level accordingly, so RPC failures are not hidden behind HTTP 200.
+func WithGRPCStatus() Option {
+	return optionFunc(func(c *config) {
+		c.grpcStatus = true
+	})
+}
+
+// WithWebhooks returns an Option that adds a "webhook" group to the access line of
+// the given routes, keyed by route template (e.g. "/hooks/github"), with the
+// signature header present, whether the Webhook verifier accepted the signature
+// and the event type, making webhook deliveries easy to debug.
+func WithWebhooks(routes map[string]Webhook) Option {
+	return optionFunc(func(c *config) {
+		c.webhooks = routes
+	})
+}
+
+// WithBaggage returns an Option that logs the W3C Baggage entries with the given
+// keys, e.g. "user.tier" or "experiment.id", as a "baggage" group, so business
+// context propagated by upstream services appears in downstream access logs.
+func WithBaggage(keys ...string) Option {
+	return optionFunc(func(c *config) {
+		if c.baggage == nil {
+			c.baggage = make(map[string]struct{}, len(keys))
+		}
+		for _, key := range keys {
+			c.baggage[key] = struct{}{}
+		}
+	})
+}
+
+// WithCorrelationID returns an Option that logs the first of headers present on
+// the request as "correlation_id", so requests can be joined across heterogeneous
+// infrastructures. traceparent, b3 and X-Amzn-Trace-Id values are reduced to their
+// trace ID. Without headers, DefaultCorrelationHeaders is used.
+func WithCorrelationID(headers ...string) Option {
+	if len(headers) == 0 {
+		headers = DefaultCorrelationHeaders()
+	}
+	return optionFunc(func(c *config) {
+		c.extraFields = append(c.extraFields, func(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+			if id := correlationID(c, headers); id != "" {
+				return evt.Str("correlation_id", id)
+			}
+			return evt
+		})
+	})
+}
+
+// WithPlatformRequestIDs returns an Option that logs the request IDs set by PaaS
+// routers under a field named after the platform, so router logs can be joined with
+// application logs: Fly-Request-Id as "fly_request_id", Render's Rndr-Id as
+// "render_request_id" and, on Heroku dynos, X-Request-Id as "heroku_request_id".
+func WithPlatformRequestIDs() Option {
+	var ids []platformRequestID
+	for _, id := range platformRequestIDs {
+		if id.env == "" || os.Getenv(id.env) != "" {
+			ids = append(ids, id)
+		}
+	}
+	return optionFunc(func(c *config) {
+		c.extraFields = append(c.extraFields, func(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+			for _, id := range ids {
+				if v := c.GetHeader(id.header); v != "" {
+					evt = evt.Str(id.field, v)
+				}
+			}
+			return evt
+		})
+	})
+}
+
+// WithRetryFields returns an Option that logs the attempt number of the request
+// as "attempt", read from the first of x-envoy-attempt-count, X-Retry-Attempt and
+// the given headers to be present, along with "is_retry" when it is above 1, so
+// duplicate processing caused by client or gateway retries can be traced.
+// Header values are attempt numbers starting at 1.
+func WithRetryFields(headers ...string) Option {
+	headers = append([]string{"X-Envoy-Attempt-Count", "X-Retry-Attempt"}, headers...)
+	return optionFunc(func(c *config) {
+		c.extraFields = append(c.extraFields, func(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+			for _, h := range headers {
+				if attempt, err := strconv.Atoi(c.GetHeader(h)); err == nil && attempt > 0 {
+					return evt.Int("attempt", attempt).Bool("is_retry", attempt > 1)
+				}
+			}
+			return evt
+		})
+	})
+}
+
+// WithProviders returns an Option that logs the fields of each FieldProvider on
+// the access line, in order, each as a group named after the provider.
+func WithProviders(providers ...FieldProvider) Option {
+	return optionFunc(func(c *config) {
+		for _, p := range providers {
+			c.extraFields = append(c.extraFields, providerFields(p))
+		}
+	})
+}
+
+// WithFlags returns an Option that logs the feature flags recorded on the context
+// under FlagsKey as a "flags" group, so experiment analysis can join on access
+// logs. The optional hook is called with the flags first.
+func WithFlags(hook FlagsHook) Option {
+	return optionFunc(func(c *config) {
+		c.extraFields = append(c.extraFields, flagsFields(hook))
+	})
+}
+
+// WithResourceUsage returns an Option that logs the heap allocations
+// ("alloc_bytes", "alloc_objects") and CPU time ("cpu_time", on Unix) measured
+// around the handler chain. The readings are process wide, so they include the
+// work of concurrent requests and background goroutines: they are an approximate
+// per-request cost, meant for capacity analysis of low traffic endpoints.
+func WithResourceUsage(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.resourceUsage = s
+	})
+}
+
+// WithProfilerLabels returns an Option that runs the handler chain with the pprof
+// labels route and method, through pprof.Do, so CPU profiles can be sliced by
+// route, and logs them as a "pprof_labels" group to tie profiles back to access
+// lines. The labeled context replaces the request context.
+func WithProfilerLabels(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.profilerLabels = s
+	})
+}
+
+// WithStreamingFields returns an Option that logs, for streamed responses written
+// in several chunks or flushed, the number of body writes as "chunks" and of
+// flushes as "flushes", to help diagnose streaming cadence issues such as proxies
+// buffering the stream.
+func WithStreamingFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.streaming = s
+	})
+}
+
+// WithProtocolFields returns an Option that logs the request protocol as "protocol"
+// ("h3", "h2", "h2c" or e.g. "http/1.1"), the negotiated ALPN protocol as "alpn",
+// the stream ID recorded with ContextWithStreamID as "stream_id", "push" when the
+// connection supports server push and the RFC 9218 Priority header as "priority",
+// to support protocol rollout analysis.
+func WithProtocolFields(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.protocol = s
+	})
+}
+
+// WithEarlyHints returns an Option that sends 1xx informational responses such as
+// 103 Early Hints written by handlers ahead of the final response, which gin's
+// writer would otherwise keep as the final status, and logs the last one as
+// "informational_status" next to the final "status" along with the time from the
+// start of the request to the first hint as "hint_latency".
+func WithEarlyHints(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.earlyHints = s
+	})
+}
+
+// WithSuperfluousWrites returns an Option that logs the status of a WriteHeader
+// call made after the response headers were already written, a double-response
+// bug gin only reports on stderr in debug mode, as "superfluous_write".
+func WithSuperfluousWrites(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.superfluousWrites = s
+	})
+}
+
+// WithSecurityFlags returns an Option that runs cheap heuristics on each request
+// and logs the anomalies found, such as conflicting Content-Length and
+// Transfer-Encoding headers ("cl_te_conflict"), repeated Content-Length headers
+// ("multiple_content_length"), header values over 8 KiB ("long_header") or null
+// bytes in the path or query ("null_byte"), as "security_flags".
+func WithSecurityFlags(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.securityFlags = s
+	})
+}
+
+// WithMaxPathLength returns an Option that truncates logged paths, including the
+// query string, longer than n bytes and appends "...+N bytes" with the number of
+// bytes removed, protecting log pipelines from multi-kilobyte URLs while keeping
+// a prefix for debugging.
+func WithMaxPathLength(n int) Option {
+	return optionFunc(func(c *config) {
+		c.maxPathLength = n
+	})
+}
+
+// WithDecodedPath returns an Option that logs the percent-decoded path in Unicode
+// normalization form C as "decoded_path", so searches for "café" match however the
+// client encoded it, and the path exactly as the client sent it as "raw_path" for
+// forensics.
+func WithDecodedPath(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.decodedPath = s
+	})
+}
+
+// WithTimestampAt returns an Option that selects whether the request log event is
+// timestamped with the completion time of the request, the default, or with its
+// arrival time, which correlates with upstream load balancer logs. With
+// TimestampAtStart, other events logged with the request context through
+// zerolog's Event.Ctx also carry the arrival time.
+func WithTimestampAt(at TimestampAt) Option {
+	return optionFunc(func(c *config) {
+		c.timestampAt = at
+	})
+}
+
+// WithSplitQuery returns an Option that logs the raw query string in a separate
+// "query" field instead of appending it to "path", so grouping by path in log
+// systems is not polluted by unique query strings.
+func WithSplitQuery(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.splitQuery = s
+	})
+}
+
+// WithSkipMatch returns an Option that selects what skip and only rules and
+// WithPathLevel match against: the URL path alone with SkipMatchPath, or the path
+// and query string with SkipMatchURL. By default, with SkipMatchDefault, only the
+// exact paths of WithSkipPath and WithOnlyPaths ignore the query string.
+func WithSkipMatch(m SkipMatch) Option {
+	return optionFunc(func(c *config) {
+		c.skipMatch = m
+	})
+}
+
+// WithContextLoggerDisabled returns an Option that skips building the request
+// logger and storing it in the context, saving allocations on every request for
+// services that never call Get. Get, GetPtr and Component panic in handlers
+// behind a middleware with this option, unless an earlier middleware set a logger.
+func WithContextLoggerDisabled(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.contextLoggerDisabled = s
+	})
+}
+
+// WithController returns an Option that lets ctl change the levels and the skip
+// rules of the middleware while it runs.
+func WithController(ctl *Controller) Option {
+	return optionFunc(func(c *config) {
+		c.controller = ctl
+	})
+}
+
+// WithStaticFields returns an Option that adds fields, such as the ones returned
+// by ServiceFields, to every event of the middleware. They are encoded once into
+// the logger, like the fields of a zerolog sub-logger, and copied into each event
+// without being serialized again. Repeated calls merge the fields. They are not
+// added to loggers set with SetBaseLogger.
+func WithStaticFields(fields map[string]any) Option {
+	return optionFunc(func(c *config) {
+		if c.staticFields == nil {
+			c.staticFields = make(map[string]any, len(fields))
+		}
+		for k, v := range fields {
+			c.staticFields[k] = v
+		}
+	})
+}
+
+// ServiceFields returns the "service" and "version" fields and the "hostname" of
+// the machine, when it is known, for WithStaticFields.
+func ServiceFields(service, version string) map[string]any {
+	fields := map[string]any{"service": service, "version": version}
+	if hostname, err := os.Hostname(); err == nil {
+		fields["hostname"] = hostname
+	}
+	return fields
+}
+
+// WithSynchronous returns an Option guaranteeing that the middleware starts no
+// goroutine and writes each event to the output with exactly one Write call,
+// from the goroutine serving the request, for environments where background
+// goroutines get in the way, such as functions frozen between invocations. It
+// disables WithAsync, WithBatching, WithResolveClientHost and the signals of
+// WithCrashDump, which New reports as conflicts.
+func WithSynchronous() Option {
+	return optionFunc(func(c *config) {
+		c.synchronous = true
+	})
+}
+
+// WithSeverityNumber returns an Option that logs, next to the textual level, the
+// OpenTelemetry SeverityNumber of every event of the middleware as
+// "severity_number" (see SeverityNumberFieldName), so OTLP-based pipelines need
+// no mapping table. Like WithStaticFields, it does not apply to loggers set with
+// SetBaseLogger.
+func WithSeverityNumber(s bool) Option {
+	return optionFunc(func(c *config) {
+		c.severityNumber = s
+	})
+}
+
+// WithName returns an Option that names the pipeline of the middleware in the
+// report of Health. A middleware replaces the one built before it under the same
+// name in the report, so rebuilding it does not add an entry. By default, a
+// Pipeline is numbered in creation order, and the middleware built by SetLogger,
+// New and ForGroup are named "default": name them to report them apart.
+func WithName(name string) Option {
+	return optionFunc(func(c *config) {
+		c.name = name
+	})
+}
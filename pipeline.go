@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pipeline is a middleware along with the writers it owns, such as the
+// BatchWriter of WithBatching, so they can be flushed and released once the
+// middleware is no longer used, e.g. when routes are rebuilt or in tests.
+// Middleware returned by SetLogger, New and ForGroup keep their writers for
+// the life of the process.
+type Pipeline struct {
+	handler gin.HandlerFunc
+	// flushers are flushed in order, from the writer closest to the logger.
+	flushers []flusher
+	// closers are closed in order, after the flushers were flushed.
+	closers []io.Closer
+	// release removes the pipeline from Health and its writers from DumpCrash.
+	release []func()
+	health  *pipelineHealth
+
+	close    sync.Once
+	closeErr error
+}
+
+// NewPipeline is like New but returns a Pipeline, whose Close releases the
+// writers of the middleware.
+func NewPipeline(opts ...Option) (*Pipeline, error) {
+	cfg := newConfig(opts)
+	err := cfg.conflicts()
+	if errors.Is(err, ErrOptionConflict) {
+		return nil, err
+	}
+	if cfg.name == "" {
+		cfg.name = nextPipelineName()
+	}
+	return cfg.pipeline(), err
+}
+
+// Handler returns the middleware.
+func (p *Pipeline) Handler() gin.HandlerFunc {
+	return p.handler
+}
+
+// Health returns the state of the writers of the pipeline, as reported by Health
+// until the pipeline is closed.
+func (p *Pipeline) Health() PipelineHealth {
+	return p.health.status()
+}
+
+// Flush writes the events buffered or queued by the writers of the pipeline.
+func (p *Pipeline) Flush() error {
+	var errs []error
+	for _, f := range p.flushers {
+		errs = append(errs, f.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Close writes the buffered and queued events, then releases the writers of
+// the pipeline, stopping the goroutines of WithAsync and WithCrashDump, and
+// removes it from Flush, Health and DumpCrash. Call it once the middleware no longer
+// serves requests: events logged afterwards are dropped.
+func (p *Pipeline) Close() error {
+	p.close.Do(func() {
+		unregisterPipeline(p)
+		errs := []error{p.Flush()}
+		for _, c := range p.closers {
+			errs = append(errs, c.Close())
+		}
+		for _, release := range p.release {
+			release()
+		}
+		p.closeErr = errors.Join(errs...)
+	})
+	return p.closeErr
+}
+
+// flusher is a writer holding events in memory.
+type flusher interface {
+	Flush() error
+}
+
+var (
+	pipelinesMu sync.Mutex
+	pipelines   []*Pipeline
+)
+
+// Flush writes the events buffered or queued by every middleware configured
+// with WithBatching or WithAsync. Call it during graceful shutdown, after the
+// HTTP server stopped accepting requests.
+func Flush() error {
+	pipelinesMu.Lock()
+	defer pipelinesMu.Unlock()
+
+	var errs []error
+	for _, p := range pipelines {
+		errs = append(errs, p.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// registerPipeline makes p part of Flush when it holds events in memory.
+func registerPipeline(p *Pipeline) {
+	if len(p.flushers) == 0 {
+		return
+	}
+	pipelinesMu.Lock()
+	defer pipelinesMu.Unlock()
+	pipelines = append(pipelines, p)
+}
+
+// unregisterPipeline removes p from Flush.
+func unregisterPipeline(p *Pipeline) {
+	pipelinesMu.Lock()
+	defer pipelinesMu.Unlock()
+	for i, q := range pipelines {
+		if q == p {
+			pipelines = append(pipelines[:i], pipelines[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// registered reports whether p is part of Flush.
+func registered(p *Pipeline) bool {
+	pipelinesMu.Lock()
+	defer pipelinesMu.Unlock()
+	for _, q := range pipelines {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPipelineClose(t *testing.T) {
+	out := new(countingWriter)
+	p, err := NewPipeline(WithWriter(out), WithBatching(100, 0, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, registered(p))
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(p.Handler())
+	r.GET("/example", func(c *gin.Context) {})
+	performRequest(r, "GET", "/example")
+	performRequest(r, "GET", "/example")
+	assert.Equal(t, 0, out.count())
+
+	assert.NoError(t, p.Close())
+	assert.Equal(t, 1, out.count())
+	assert.Equal(t, 2, strings.Count(out.writes[0], "path=/example"))
+	assert.False(t, registered(p))
+	assert.NoError(t, p.Close())
+}
+
+func TestPipelineCloseAsync(t *testing.T) {
+	out := new(countingWriter)
+	p, err := NewPipeline(WithWriter(out), WithAsync(16), WithBackpressurePolicy(BackpressureSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, Health().Backpressure, "sample")
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(p.Handler())
+	r.GET("/example", func(c *gin.Context) {})
+	performRequest(r, "GET", "/example")
+
+	assert.NoError(t, p.Close())
+	assert.Equal(t, 1, out.count())
+	assert.NotContains(t, Health().Backpressure, "sample")
+	assert.ErrorIs(t, p.Flush(), ErrWriterClosed)
+}
+
+func TestPipelineCloseWhileLogging(t *testing.T) {
+	out := new(countingWriter)
+	p, err := NewPipeline(WithWriter(out), WithAsync(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(p.Handler())
+	r.GET("/example", func(c *gin.Context) {})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				performRequest(r, "GET", "/example")
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	assert.NoError(t, p.Close())
+	wg.Wait()
+
+	assert.Equal(t, int64(400), int64(out.count())+p.Health().DroppedEvents)
+}
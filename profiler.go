@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// profilerLabels returns the pprof labels of the request: its route and method.
+func profilerLabels(c *gin.Context) pprof.LabelSet {
+	route := c.FullPath()
+	if route == "" {
+		route = unmatchedRoute
+	}
+	return pprof.Labels("route", route, "method", c.Request.Method)
+}
+
+// profilerLabelsFields adds the pprof labels the handler chain ran with as a
+// "pprof_labels" group.
+func profilerLabelsFields(evt *zerolog.Event, labels pprof.LabelSet) *zerolog.Event {
+	dict := zerolog.Dict()
+	pprof.ForLabels(pprof.WithLabels(context.Background(), labels), func(key, value string) bool {
+		dict = dict.Str(key, value)
+		return true
+	})
+	return evt.Dict("pprof_labels", dict)
+}
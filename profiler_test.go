@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerProfilerLabels(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	var route, method string
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithProfilerLabels(true)))
+	r.GET("/users/:id", func(c *gin.Context) {
+		route, _ = pprof.Label(c.Request.Context(), "route")
+		method, _ = pprof.Label(c.Request.Context(), "method")
+	})
+
+	performRequest(r, "GET", "/users/42")
+	assert.Equal(t, "/users/:id", route)
+	assert.Equal(t, "GET", method)
+	assert.Contains(t, buffer.String(), `pprof_labels={"method":"GET","route":"/users/:id"}`)
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// parseBaggage returns the entries of a W3C Baggage header whose key is in allow,
+// with their properties removed and their values percent-decoded.
+func parseBaggage(header string, allow map[string]struct{}) map[string]string {
+	var entries map[string]string
+	for _, member := range strings.Split(header, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		key, value, ok := strings.Cut(member, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := allow[key]; !ok {
+			continue
+		}
+		if decoded, err := url.PathUnescape(strings.TrimSpace(value)); err == nil {
+			value = decoded
+		}
+		if entries == nil {
+			entries = make(map[string]string)
+		}
+		entries[key] = value
+	}
+	return entries
+}
+
+// baggageFields adds the allowed W3C Baggage entries of the request as a "baggage" group.
+func baggageFields(c *gin.Context, evt *zerolog.Event, allow map[string]struct{}, redactors []Redactor) *zerolog.Event {
+	var dict *zerolog.Event
+	for _, header := range c.Request.Header.Values("Baggage") {
+		for k, v := range parseBaggage(header, allow) {
+			if dict == nil {
+				dict = zerolog.Dict()
+			}
+			dict = dict.Str(k, redactString(redactors, k, v))
+		}
+	}
+	if dict == nil {
+		return evt
+	}
+	return evt.Dict("baggage", dict)
+}
+
+// DefaultCorrelationHeaders returns the headers WithCorrelationID checks when
+// none are given, in order.
+func DefaultCorrelationHeaders() []string {
+	return []string{
+		"traceparent",
+		"b3",
+		"X-B3-TraceId",
+		"X-Request-ID",
+		"X-Correlation-ID",
+		"X-Amzn-Trace-Id",
+	}
+}
+
+// correlationID returns the value of the first of headers present on the
+// request, reduced to the trace ID for the trace context formats.
+func correlationID(c *gin.Context, headers []string) string {
+	for _, h := range headers {
+		v := strings.TrimSpace(c.GetHeader(h))
+		if v == "" {
+			continue
+		}
+		switch strings.ToLower(h) {
+		case "traceparent":
+			if parts := strings.Split(v, "-"); len(parts) >= 4 && len(parts[1]) == 32 {
+				return parts[1]
+			}
+			continue
+		case "b3":
+			// b3: {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}, or a lone sampling state.
+			if id, _, ok := strings.Cut(v, "-"); ok {
+				return id
+			}
+			continue
+		case "x-amzn-trace-id":
+			// X-Amzn-Trace-Id: Root=1-5759e988-bd862e3fe1be46a994272793;Parent=...;Sampled=1
+			for _, part := range strings.Split(v, ";") {
+				if root, ok := strings.CutPrefix(strings.TrimSpace(part), "Root="); ok {
+					return root
+				}
+			}
+			continue
+		}
+		return v
+	}
+	return ""
+}
+
+// platformRequestID is a request ID header set by a PaaS router.
+type platformRequestID struct {
+	header string
+	field  string
+	// env, when set, is an environment variable that must be present for the
+	// header to be attributed to the platform, for generic header names.
+	env string
+}
+
+// platformRequestIDs are the request ID headers recognized by WithPlatformRequestIDs.
+var platformRequestIDs = []platformRequestID{
+	{header: "X-Request-Id", field: "heroku_request_id", env: "DYNO"},
+	{header: "Fly-Request-Id", field: "fly_request_id"},
+	{header: "Rndr-Id", field: "render_request_id"},
+}
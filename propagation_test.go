@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBaggage(t *testing.T) {
+	allow := map[string]struct{}{"user.tier": {}, "experiment.id": {}}
+	entries := parseBaggage("user.tier=gold;ttl=30, other=x,experiment.id=checkout%20v2,broken", allow)
+	assert.Equal(t, map[string]string{"user.tier": "gold", "experiment.id": "checkout v2"}, entries)
+	assert.Nil(t, parseBaggage("other=x", allow))
+}
+
+func TestLoggerBaggage(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithBaggage("user.tier", "user.email")))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example",
+		header{"Baggage", "user.tier=gold,session=s1"},
+		header{"Baggage", "user.email=jane%40example.com"})
+	assert.Contains(t, buffer.String(), `baggage={"user.email":"[EMAIL]","user.tier":"gold"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example")
+	assert.NotContains(t, buffer.String(), "baggage")
+}
+
+func TestLoggerCorrelationID(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/default", SetLogger(WithWriter(buffer), WithCorrelationID()), func(c *gin.Context) {})
+	r.GET("/custom", SetLogger(WithWriter(buffer), WithCorrelationID("X-Trace")), func(c *gin.Context) {})
+
+	tests := []struct {
+		name    string
+		path    string
+		headers []header
+		want    string
+	}{
+		{"traceparent", "/default", []header{
+			{"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			{"X-Request-ID", "req-1"},
+		}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		{"invalid traceparent", "/default", []header{
+			{"traceparent", "garbage"},
+			{"X-Request-ID", "req-1"},
+		}, "req-1"},
+		{"b3", "/default", []header{{"b3", "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1"}}, "80f198ee56343ba864fe8b2a57d3eff7"},
+		{"b3 multi", "/default", []header{{"X-B3-TraceId", "463ac35c9f6413ad"}}, "463ac35c9f6413ad"},
+		{"amazon", "/default", []header{{"X-Amzn-Trace-Id", "Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=1"}}, "1-5759e988-bd862e3fe1be46a994272793"},
+		{"correlation", "/default", []header{{"X-Correlation-ID", "corr-9"}}, "corr-9"},
+		{"custom", "/custom", []header{{"X-Trace", "t-1"}, {"X-Request-ID", "req-1"}}, "t-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer.Reset()
+			performRequest(r, "GET", tt.path, tt.headers...)
+			assert.Contains(t, buffer.String(), "correlation_id="+tt.want+" ")
+		})
+	}
+
+	buffer.Reset()
+	performRequest(r, "GET", "/custom", header{"X-Request-ID", "req-1"})
+	assert.NotContains(t, buffer.String(), "correlation_id")
+}
+
+func TestLoggerPlatformRequestIDs(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/off-heroku", SetLogger(WithWriter(buffer), WithPlatformRequestIDs()), func(c *gin.Context) {})
+	t.Setenv("DYNO", "web.1")
+	r.GET("/heroku", SetLogger(WithWriter(buffer), WithPlatformRequestIDs()), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/off-heroku",
+		header{"X-Request-Id", "h-1"},
+		header{"Fly-Request-Id", "01H-fly"},
+		header{"Rndr-Id", "rndr-1"})
+	assert.Contains(t, buffer.String(), "fly_request_id=01H-fly")
+	assert.Contains(t, buffer.String(), "render_request_id=rndr-1")
+	assert.NotContains(t, buffer.String(), "heroku_request_id")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/heroku", header{"X-Request-Id", "h-1"})
+	assert.Contains(t, buffer.String(), "heroku_request_id=h-1")
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// streamIDKey is the request context key holding the HTTP/2 or HTTP/3 stream ID.
+type streamIDKey struct{}
+
+// ContextWithStreamID returns a copy of ctx carrying the stream ID id of the request.
+// net/http does not expose stream IDs, so HTTP/2 and HTTP/3 server integrations that
+// know them can record them for WithProtocolFields with this function.
+func ContextWithStreamID(ctx context.Context, id uint64) context.Context {
+	return context.WithValue(ctx, streamIDKey{}, id)
+}
+
+// protocolName returns the protocol of the request r: "h3", "h2", "h2c" (HTTP/2
+// without TLS) or the lower-cased request proto such as "http/1.1".
+func protocolName(r *http.Request) string {
+	switch r.ProtoMajor {
+	case 3:
+		return "h3"
+	case 2:
+		if r.TLS == nil {
+			return "h2c"
+		}
+		return "h2"
+	}
+	return strings.ToLower(r.Proto)
+}
+
+// parsePriority parses the RFC 9218 Priority header value v into its urgency,
+// defaulting to 3, and incremental flag.
+func parsePriority(v string) (int, bool) {
+	urgency, incremental := 3, false
+	for _, p := range strings.Split(v, ",") {
+		k, val, _ := strings.Cut(strings.TrimSpace(p), "=")
+		switch k {
+		case "u":
+			if u, err := strconv.Atoi(val); err == nil && u >= 0 && u <= 7 {
+				urgency = u
+			}
+		case "i":
+			incremental = val == "" || val == "?1"
+		}
+	}
+	return urgency, incremental
+}
+
+// protocolFields adds the protocol, the negotiated ALPN protocol, the stream ID,
+// server push support and the request priority of c to evt.
+func protocolFields(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+	r := c.Request
+	evt = evt.Str("protocol", protocolName(r))
+	if r.TLS != nil && r.TLS.NegotiatedProtocol != "" {
+		evt = evt.Str("alpn", r.TLS.NegotiatedProtocol)
+	}
+	if id, ok := r.Context().Value(streamIDKey{}).(uint64); ok {
+		evt = evt.Uint64("stream_id", id)
+	}
+	if r.ProtoMajor >= 2 && c.Writer.Pusher() != nil {
+		evt = evt.Bool("push", true)
+	}
+	if v := r.Header.Get("Priority"); v != "" {
+		urgency, incremental := parsePriority(v)
+		evt = evt.Dict("priority", zerolog.Dict().Int("urgency", urgency).Bool("incremental", incremental))
+	}
+	return evt
+}
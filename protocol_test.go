@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePriority(t *testing.T) {
+	u, i := parsePriority("")
+	assert.Equal(t, 3, u)
+	assert.False(t, i)
+
+	u, i = parsePriority("u=1, i")
+	assert.Equal(t, 1, u)
+	assert.True(t, i)
+
+	u, i = parsePriority("u=9, i=?0")
+	assert.Equal(t, 3, u)
+	assert.False(t, i)
+}
+
+func TestLoggerProtocolFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithProtocolFields(true)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "protocol=http/1.1")
+	assert.NotContains(t, buffer.String(), "alpn")
+
+	buffer.Reset()
+	req := httptest.NewRequest("GET", "/example", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.TLS = &tls.ConnectionState{NegotiatedProtocol: "h2"}
+	req.Header.Set("Priority", "u=1, i")
+	req = req.WithContext(ContextWithStreamID(req.Context(), 7))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Contains(t, buffer.String(), "protocol=h2")
+	assert.Contains(t, buffer.String(), "alpn=h2")
+	assert.Contains(t, buffer.String(), "stream_id=7")
+	assert.Contains(t, buffer.String(), `priority={"incremental":true,"urgency":1}`)
+
+	buffer.Reset()
+	req = httptest.NewRequest("GET", "/example", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Contains(t, buffer.String(), "protocol=h2c")
+}
+
+func TestProtocolName(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Proto, req.ProtoMajor = "HTTP/3.0", 3
+	assert.Equal(t, "h3", protocolName(req))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.0", 1, 0
+	assert.Equal(t, "http/1.0", protocolName(req))
+}
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// FieldProvider is a reusable enrichment plugin, such as geo lookup, auth or
+// feature flags, registered with WithProviders. Its fields are logged on the access
+// line as a group named after the provider.
+type FieldProvider interface {
+	// Name returns the name of the group the fields are logged under.
+	Name() string
+	// Fields returns the fields for the request, once the handler chain returned.
+	// A nil or empty map logs nothing.
+	Fields(c *gin.Context) map[string]any
+}
+
+// providerFields returns a field extractor logging the fields of p.
+func providerFields(p FieldProvider) EventFn {
+	return func(c *gin.Context, evt *zerolog.Event) *zerolog.Event {
+		fields := p.Fields(c)
+		if len(fields) == 0 {
+			return evt
+		}
+		return evt.Dict(p.Name(), zerolog.Dict().Fields(fields))
+	}
+}
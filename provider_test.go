@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type geoProvider struct{}
+
+func (geoProvider) Name() string { return "geo" }
+
+func (geoProvider) Fields(c *gin.Context) map[string]any {
+	if c.GetHeader("CF-IPCountry") == "" {
+		return nil
+	}
+	return map[string]any{"country": c.GetHeader("CF-IPCountry"), "eu": c.GetHeader("CF-IPCountry") == "FR"}
+}
+
+type tenantProvider struct{}
+
+func (tenantProvider) Name() string { return "tenant" }
+
+func (tenantProvider) Fields(c *gin.Context) map[string]any {
+	return map[string]any{"id": c.GetString("tenant")}
+}
+
+func TestLoggerProviders(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithProviders(geoProvider{}, tenantProvider{})))
+	r.GET("/example", func(c *gin.Context) {
+		c.Set("tenant", "acme")
+	})
+
+	performRequest(r, "GET", "/example", header{"CF-IPCountry", "FR"})
+	assert.Contains(t, buffer.String(), `geo={"country":"FR","eu":true}`)
+	assert.Contains(t, buffer.String(), `tenant={"id":"acme"}`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example")
+	assert.NotContains(t, buffer.String(), "geo=")
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces values removed by a key based Redactor.
+const redacted = "[REDACTED]"
+
+// Redactor scrubs sensitive data from captured content, such as request body
+// fields and headers, before it is logged. key is the header name or the JSON key
+// the value was found under, value is the captured content.
+type Redactor interface {
+	Redact(key, value string) string
+}
+
+// RedactorFunc is an adapter to allow the use of ordinary functions as Redactor.
+type RedactorFunc func(key, value string) string
+
+// Redact calls f(key, value).
+func (f RedactorFunc) Redact(key, value string) string {
+	return f(key, value)
+}
+
+// RedactPattern returns a Redactor that replaces every match of re in the value
+// with replacement. The replacement may reference capture groups as in
+// regexp.Regexp.ReplaceAllString.
+func RedactPattern(re *regexp.Regexp, replacement string) Redactor {
+	return RedactorFunc(func(_, value string) string {
+		return re.ReplaceAllString(value, replacement)
+	})
+}
+
+// RedactKeys returns a Redactor that replaces the whole value of the given
+// header names or JSON keys, compared case-insensitively, with "[REDACTED]".
+func RedactKeys(keys ...string) Redactor {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = struct{}{}
+	}
+	return RedactorFunc(func(key, value string) string {
+		if _, ok := set[strings.ToLower(key)]; ok {
+			return redacted
+		}
+		return value
+	})
+}
+
+var (
+	rxCreditCard = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	rxEmail      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	rxSSN        = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+)
+
+// DefaultRedactors returns the redactors applied when WithRedactors is not used.
+// They mask credit card numbers, email addresses and US social security numbers,
+// and drop the values of common credential keys such as password, token and secret.
+func DefaultRedactors() []Redactor {
+	return []Redactor{
+		RedactKeys(
+			"password", "passwd", "secret", "token", "access_token", "refresh_token",
+			"api_key", "apikey", "authorization", "cookie", "set-cookie", "ssn",
+			"card_number", "cvv",
+		),
+		RedactPattern(rxCreditCard, "[CARD]"),
+		RedactPattern(rxEmail, "[EMAIL]"),
+		RedactPattern(rxSSN, "[SSN]"),
+	}
+}
+
+// redactString runs value through every redactor.
+func redactString(redactors []Redactor, key, value string) string {
+	for _, r := range redactors {
+		value = r.Redact(key, value)
+	}
+	return value
+}
+
+// redactValue scrubs a decoded JSON value. Object members are redacted under
+// their own key, array elements under the key of the array. Values of other
+// types than strings are only removed by key based redactors.
+func redactValue(redactors []Redactor, key string, v any) any {
+	switch node := v.(type) {
+	case string:
+		return redactString(redactors, key, node)
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			out[k] = redactValue(redactors, k, child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			out[i] = redactValue(redactors, key, child)
+		}
+		return out
+	default:
+		// Pattern redactors leave an empty value untouched, key redactors don't.
+		if redactString(redactors, key, "") == redacted {
+			return redacted
+		}
+		return v
+	}
+}
+
+// redactHeader scrubs a header value before it is logged. Credentials carried by
+// Authorization and Proxy-Authorization are always removed, keeping only the auth
+// scheme, regardless of the configured redactors.
+func redactHeader(redactors []Redactor, name, value string) string {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization":
+		if scheme, _, ok := strings.Cut(value, " "); ok {
+			return scheme + " " + redacted
+		}
+		return redacted
+	}
+	return redactString(redactors, name, value)
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRedactors(t *testing.T) {
+	redactors := DefaultRedactors()
+	assert.Equal(t, "[REDACTED]", redactString(redactors, "Password", "hunter2"))
+	assert.Equal(t, "card [CARD]", redactString(redactors, "note", "card 4111 1111 1111 1111"))
+	assert.Equal(t, "mail [EMAIL]", redactString(redactors, "note", "mail jane@example.com"))
+	assert.Equal(t, "ssn [SSN]", redactString(redactors, "note", "ssn 123-45-6789"))
+	assert.Equal(t, "order 42", redactString(redactors, "note", "order 42"))
+
+	v := redactValue(redactors, "user", map[string]any{
+		"name":  "jane",
+		"email": "jane@example.com",
+		"pin":   "1234",
+		"token": 42,
+		"cards": []any{"4111111111111111"},
+	})
+	assert.Equal(t, map[string]any{
+		"name":  "jane",
+		"email": "[EMAIL]",
+		"pin":   "1234",
+		"token": "[REDACTED]",
+		"cards": []any{"[CARD]"},
+	}, v)
+}
+
+func TestLoggerRedactors(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.POST("/default", SetLogger(
+		WithWriter(buffer),
+		WithBodyFields("user"),
+	), func(c *gin.Context) {})
+	r.POST("/custom", SetLogger(
+		WithWriter(buffer),
+		WithBodyFields("user"),
+		WithRedactors(RedactPattern(regexp.MustCompile(`jane`), "***"), RedactKeys("pin")),
+	), func(c *gin.Context) {})
+	r.POST("/disabled", SetLogger(
+		WithWriter(buffer),
+		WithBodyFields("user"),
+		WithRedactors(),
+	), func(c *gin.Context) {})
+
+	payload := `{"user":{"name":"jane","email":"jane@example.com","password":"hunter2","pin":"1234"}}`
+	performBodyRequest(r, "POST", "/default", "application/json", payload)
+	assert.NotContains(t, buffer.String(), "hunter2")
+	assert.NotContains(t, buffer.String(), "jane@example.com")
+	assert.Contains(t, buffer.String(), `"pin":"1234"`)
+
+	buffer.Reset()
+	performBodyRequest(r, "POST", "/custom", "application/json", payload)
+	assert.Contains(t, buffer.String(), "hunter2")
+	assert.Contains(t, buffer.String(), `"name":"***"`)
+	assert.Contains(t, buffer.String(), `"pin":"[REDACTED]"`)
+
+	buffer.Reset()
+	performBodyRequest(r, "POST", "/disabled", "application/json", payload)
+	assert.True(t, strings.Contains(buffer.String(), "jane@example.com"))
+}
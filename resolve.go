@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxResolvedHosts bounds the number of client IPs the resolver caches.
+	maxResolvedHosts = 4096
+	// resolveTimeout bounds a single reverse lookup.
+	resolveTimeout = 2 * time.Second
+)
+
+// lookupAddr performs reverse lookups; tests replace it and restore defaultLookupAddr.
+var (
+	defaultLookupAddr = net.DefaultResolver.LookupAddr
+	lookupAddr        = defaultLookupAddr
+)
+
+// hostEntry is a cached reverse lookup result. An empty host records a failed lookup.
+type hostEntry struct {
+	host    string
+	expires time.Time
+	pending bool
+}
+
+// hostResolver reverse-resolves client IPs in the background and caches the
+// results, so requests never wait on DNS: the host of an IP is logged from the
+// first request completing after its lookup finished.
+type hostResolver struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*hostEntry
+}
+
+func newHostResolver(ttl time.Duration, now func() time.Time) *hostResolver {
+	return &hostResolver{ttl: ttl, now: now, hosts: make(map[string]*hostEntry)}
+}
+
+// host returns the cached host name of ip, starting a lookup when there is none.
+func (r *hostResolver) host(ip string) (string, bool) {
+	if ip == "" {
+		return "", false
+	}
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e, ok := r.hosts[ip]; ok && (e.pending || now.Before(e.expires)) {
+		return e.host, e.host != ""
+	}
+	if len(r.hosts) >= maxResolvedHosts {
+		r.evict(now)
+		if len(r.hosts) >= maxResolvedHosts {
+			return "", false
+		}
+	}
+	r.hosts[ip] = &hostEntry{pending: true}
+	go r.resolve(ip)
+	return "", false
+}
+
+// evict removes the expired entries. It must be called with mu held.
+func (r *hostResolver) evict(now time.Time) {
+	for ip, e := range r.hosts {
+		if !e.pending && !now.Before(e.expires) {
+			delete(r.hosts, ip)
+		}
+	}
+}
+
+// resolve looks ip up and caches the result.
+func (r *hostResolver) resolve(ip string) {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+
+	var host string
+	if names, err := lookupAddr(ctx, ip); err == nil && len(names) > 0 {
+		host = strings.TrimSuffix(names[0], ".")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hosts[ip] = &hostEntry{host: host, expires: r.now().Add(r.ttl)}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerResolveClientHost(t *testing.T) {
+	var lookups atomic.Int32
+	lookupAddr = func(_ context.Context, addr string) ([]string, error) {
+		lookups.Add(1)
+		if addr == "192.0.2.1" {
+			return []string{"build-01.corp.example."}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	defer func() { lookupAddr = defaultLookupAddr }()
+
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithResolveClientHost(true, time.Hour)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example")
+	assert.Eventually(t, func() bool {
+		buffer.Reset()
+		performRequest(r, "GET", "/example")
+		return bytes.Contains(buffer.Bytes(), []byte("client_host=build-01.corp.example"))
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), lookups.Load())
+}
+
+func TestHostResolverCache(t *testing.T) {
+	done := make(chan struct{}, 1)
+	lookupAddr = func(context.Context, string) ([]string, error) {
+		defer func() { done <- struct{}{} }()
+		return nil, errors.New("no such host")
+	}
+	defer func() { lookupAddr = defaultLookupAddr }()
+
+	clock := &stepClock{now: time.Now(), step: time.Minute}
+	r := newHostResolver(90*time.Second, clock.Now)
+	_, ok := r.host("198.51.100.7")
+	assert.False(t, ok)
+	<-done
+
+	_, ok = r.host("198.51.100.7")
+	assert.False(t, ok)
+	select {
+	case <-done:
+		t.Fatal("failed lookup was not cached")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	_, _ = r.host("198.51.100.7")
+	<-done
+
+	_, ok = r.host("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"runtime/metrics"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// resourceMetrics are the runtime metrics sampled by WithResourceUsage.
+var resourceMetrics = []string{
+	"/gc/heap/allocs:bytes",
+	"/gc/heap/allocs:objects",
+}
+
+// resourceSample is a reading of the process resource usage.
+type resourceSample struct {
+	allocBytes   uint64
+	allocObjects uint64
+	cpu          time.Duration
+	hasCPU       bool
+}
+
+// sampleResources reads the cumulative heap allocations and CPU time of the process.
+func sampleResources() resourceSample {
+	samples := make([]metrics.Sample, len(resourceMetrics))
+	for i, name := range resourceMetrics {
+		samples[i].Name = name
+	}
+	metrics.Read(samples)
+
+	var s resourceSample
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		s.allocBytes = samples[0].Value.Uint64()
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		s.allocObjects = samples[1].Value.Uint64()
+	}
+	s.cpu, s.hasCPU = processCPUTime()
+	return s
+}
+
+// resourceFields adds the resources used between before and after. The readings are
+// process wide, so concurrent requests are included: the values are only meaningful
+// as an approximation, on low traffic endpoints.
+func resourceFields(evt *zerolog.Event, before, after resourceSample) *zerolog.Event {
+	evt = evt.Uint64("alloc_bytes", after.allocBytes-before.allocBytes).
+		Uint64("alloc_objects", after.allocObjects-before.allocObjects)
+	if before.hasCPU && after.hasCPU {
+		evt = evt.Dur("cpu_time", after.cpu-before.cpu)
+	}
+	return evt
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package logger
+
+import "time"
+
+// processCPUTime is not supported on this platform.
+func processCPUTime() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+var sink []byte
+
+func TestLoggerResourceUsage(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithResourceUsage(true), WithLogger(func(_ *gin.Context, l zerolog.Logger) zerolog.Logger {
+		return l.Output(buffer)
+	})))
+	r.GET("/alloc", func(c *gin.Context) {
+		for i := 0; i < 64; i++ {
+			sink = make([]byte, 64<<10)
+		}
+		deadline := time.Now().Add(20 * time.Millisecond)
+		for time.Now().Before(deadline) {
+			runtime.Gosched()
+		}
+	})
+
+	performRequest(r, "GET", "/alloc")
+	var evt struct {
+		AllocBytes   uint64   `json:"alloc_bytes"`
+		AllocObjects uint64   `json:"alloc_objects"`
+		CPUTime      *float64 `json:"cpu_time"`
+	}
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &evt))
+	assert.GreaterOrEqual(t, evt.AllocBytes, uint64(64*64<<10))
+	assert.Positive(t, evt.AllocObjects)
+	if runtime.GOOS != "windows" && runtime.GOOS != "plan9" {
+		assert.NotNil(t, evt.CPUTime)
+	}
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseInfoKey is the context key the ResponseInfo is stored under.
+const responseInfoKey = "_gin-contrib/logger_response_info_"
+
+// Outcome classifies the result of a request.
+type Outcome string
+
+// Outcomes of a request, derived from its status code.
+const (
+	OutcomeSuccess     Outcome = "success"
+	OutcomeClientError Outcome = "client_error"
+	OutcomeServerError Outcome = "server_error"
+)
+
+// outcomeOf returns the Outcome of the status code.
+func outcomeOf(status int) Outcome {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return OutcomeServerError
+	case status >= http.StatusBadRequest:
+		return OutcomeClientError
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// ResponseInfo holds the measurements SetLogger made for a request, so other
+// middleware such as metrics or billing can reuse them instead of recomputing them.
+type ResponseInfo struct {
+	// Status is the response status code.
+	Status int
+	// Latency is the time spent in the handler chain.
+	Latency time.Duration
+	// Bytes is the size of the response body.
+	Bytes int
+	// Outcome classifies the status code.
+	Outcome Outcome
+	// Sampled reports whether the access line was written, i.e. the request
+	// was neither skipped nor dropped by the sampler.
+	Sampled bool
+}
+
+// GetResponseInfo returns the ResponseInfo of a request computed by SetLogger.
+// It is set once the handler chain returned, for every request including skipped
+// ones, so it is available to middleware registered before SetLogger after their
+// call to c.Next().
+func GetResponseInfo(c *gin.Context) (ResponseInfo, bool) {
+	v, ok := c.Get(responseInfoKey)
+	if !ok {
+		return ResponseInfo{}, false
+	}
+	info, ok := v.(ResponseInfo)
+	return info, ok
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResponseInfo(t *testing.T) {
+	var infos []ResponseInfo
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		_, ok := GetResponseInfo(c)
+		assert.False(t, ok)
+		c.Next()
+		info, ok := GetResponseInfo(c)
+		assert.True(t, ok)
+		infos = append(infos, info)
+	})
+	r.Use(SetLogger(WithWriter(new(bytes.Buffer)), WithSkipPath([]string{"/skip"})))
+	r.GET("/ok", func(c *gin.Context) { c.String(http.StatusOK, "hello") })
+	r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+	r.GET("/skip", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	performRequest(r, "GET", "/ok")
+	performRequest(r, "GET", "/fail")
+	performRequest(r, "GET", "/skip")
+
+	assert.Len(t, infos, 3)
+	assert.Equal(t, http.StatusOK, infos[0].Status)
+	assert.Equal(t, 5, infos[0].Bytes)
+	assert.Equal(t, OutcomeSuccess, infos[0].Outcome)
+	assert.True(t, infos[0].Sampled)
+	assert.Equal(t, OutcomeServerError, infos[1].Outcome)
+	assert.Equal(t, OutcomeClientError, infos[2].Outcome)
+	assert.False(t, infos[2].Sampled)
+	for _, info := range infos {
+		assert.Positive(t, info.Latency)
+	}
+}
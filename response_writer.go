@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"hash"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseWriter wraps the gin.ResponseWriter for the duration of a request to
+// observe what the handler chain writes. It is only installed when an option
+// needs it, so the default configuration keeps using gin's writer directly.
+type responseWriter struct {
+	gin.ResponseWriter
+	c *gin.Context
+
+	// wrapped reports whether a middleware registered after SetLogger replaced
+	// c.Writer with its own writer (e.g. a compression writer) and the logger
+	// wrapped that replacement with a handlerWriter.
+	wrapped bool
+	// handlerSize is the number of bytes the handler wrote to the replacement writer.
+	handlerSize int
+	// hash, when set, receives every byte of the response body.
+	hash hash.Hash
+
+	now func() time.Time
+	// firstWrite is when the first byte of the body was written.
+	firstWrite time.Time
+	// timing enables measuring writeTime.
+	timing bool
+	// writeTime is the time spent in Write, WriteString and Flush.
+	writeTime time.Duration
+	// chunks and flushes count the Write and WriteString calls and the Flush calls.
+	chunks, flushes int
+	// hints enables sending 1xx informational responses past gin's writer, which
+	// would otherwise keep them as the final status.
+	hints bool
+	// informational is the last 1xx status sent before the final one and hintAt when it was sent.
+	informational int
+	hintAt        time.Time
+	// superfluous is the status of the last WriteHeader call made after the headers
+	// were written, which gin ignores.
+	superfluous int
+}
+
+func newResponseWriter(c *gin.Context, now func() time.Time) *responseWriter {
+	w := &responseWriter{ResponseWriter: c.Writer, c: c, now: now}
+	c.Writer = w
+	return w
+}
+
+// adopt wraps a writer that replaced this one in the context, so the bytes
+// written by the handler are counted before a downstream middleware transforms them.
+// It runs when the status is set, which gin's render helpers do before writing
+// the body, so responses written that way are measured in full.
+func (w *responseWriter) adopt() {
+	if w.wrapped || w.c.Writer == gin.ResponseWriter(w) {
+		return
+	}
+	w.wrapped = true
+	w.c.Writer = &handlerWriter{ResponseWriter: w.c.Writer, owner: w}
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.hints && code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		if u, ok := w.ResponseWriter.(interface{ Unwrap() http.ResponseWriter }); ok && !w.Written() {
+			w.informational = code
+			if w.hintAt.IsZero() {
+				w.hintAt = w.now()
+			}
+			u.Unwrap().WriteHeader(code)
+			return
+		}
+	}
+	if code > 0 && w.Written() && code != w.Status() {
+		w.superfluous = code
+	}
+	w.adopt()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// written records the time of the first write.
+func (w *responseWriter) written() {
+	if w.firstWrite.IsZero() {
+		w.firstWrite = w.now()
+	}
+}
+
+// begin returns the start time of a write when timing is enabled.
+func (w *responseWriter) begin() time.Time {
+	if !w.timing {
+		return time.Time{}
+	}
+	return w.now()
+}
+
+// end adds the time elapsed since began to writeTime.
+func (w *responseWriter) end(began time.Time) {
+	if w.timing {
+		w.writeTime += w.now().Sub(began)
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written()
+	w.chunks++
+	began := w.begin()
+	n, err := w.ResponseWriter.Write(b)
+	w.end(began)
+	if w.hash != nil {
+		w.hash.Write(b[:n])
+	}
+	return n, err
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	w.written()
+	w.chunks++
+	began := w.begin()
+	n, err := w.ResponseWriter.WriteString(s)
+	w.end(began)
+	if w.hash != nil {
+		w.hash.Write([]byte(s[:n]))
+	}
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	w.flushes++
+	began := w.begin()
+	w.ResponseWriter.Flush()
+	w.end(began)
+}
+
+// handlerWriter counts the bytes the handler writes to a writer installed by a
+// middleware registered after SetLogger.
+type handlerWriter struct {
+	gin.ResponseWriter
+	owner *responseWriter
+}
+
+func (w *handlerWriter) Write(b []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(b)
+	w.owner.handlerSize += n
+	return n, err
+}
+
+func (w *handlerWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.owner.handlerSize += n
+	return n, err
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// Sampler decides whether a successful request is logged. Requests with a
+// status code >= 400 are always logged.
+type Sampler interface {
+	Sample(c *gin.Context) bool
+}
+
+// SamplerFunc is an adapter to allow the use of ordinary functions as Sampler.
+type SamplerFunc func(c *gin.Context) bool
+
+// Sample calls f(c).
+func (f SamplerFunc) Sample(c *gin.Context) bool {
+	return f(c)
+}
+
+// randomSampler keeps a random fraction of requests.
+type randomSampler struct {
+	rate float64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// RandomSampler returns a Sampler keeping the given fraction of requests, drawn
+// from a random source seeded with seed, so sampling is reproducible in tests.
+func RandomSampler(rate float64, seed int64) Sampler {
+	return &randomSampler{rate: rate, rng: rand.New(rand.NewSource(seed))} //nolint:gosec
+}
+
+func (s *randomSampler) Sample(*gin.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64() < s.rate
+}
+
+// HashSampler returns a Sampler keeping the given fraction of requests based on
+// the 64-bit xxHash of the key returned by key: a request is kept when its hash,
+// scaled to [0, 1), is below rate. Every service using the same key and rate
+// samples the same requests of a call chain. Requests without key are kept.
+// A nil key uses DefaultSampleKey.
+func HashSampler(rate float64, key func(*gin.Context) string) Sampler {
+	if key == nil {
+		key = DefaultSampleKey
+	}
+	var threshold uint64
+	if rate > 0 {
+		threshold = uint64(rate * math.MaxUint64)
+	}
+	return SamplerFunc(func(c *gin.Context) bool {
+		k := key(c)
+		if k == "" || rate >= 1 {
+			return true
+		}
+		return xxhash.Sum64String(k) < threshold
+	})
+}
+
+// DefaultSampleKey returns the trace ID of a W3C traceparent header, or the
+// X-Request-ID header when there is none.
+func DefaultSampleKey(c *gin.Context) string {
+	if tp := c.GetHeader("traceparent"); tp != "" {
+		if parts := strings.Split(tp, "-"); len(parts) >= 4 && len(parts[1]) == 32 {
+			return parts[1]
+		}
+	}
+	return c.GetHeader("X-Request-ID")
+}
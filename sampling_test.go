@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomSamplerSeeded(t *testing.T) {
+	a, b := RandomSampler(0.5, 42), RandomSampler(0.5, 42)
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		x, y := a.Sample(nil), b.Sample(nil)
+		assert.Equal(t, x, y)
+		if x {
+			kept++
+		}
+	}
+	assert.InDelta(t, 500, kept, 100)
+}
+
+func TestHashSampler(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	s := HashSampler(0.5, nil)
+	other := HashSampler(0.5, nil)
+	kept := 0
+	for i := 0; i < 1000; i++ {
+		c, _ := gin.CreateTestContext(nil)
+		c.Request, _ = http.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("X-Request-ID", fmt.Sprintf("req-%d", i))
+		decision := s.Sample(c)
+		assert.Equal(t, decision, other.Sample(c))
+		if decision {
+			kept++
+		}
+	}
+	assert.InDelta(t, 500, kept, 100)
+
+	c, _ := gin.CreateTestContext(nil)
+	c.Request, _ = http.NewRequest("GET", "/", nil)
+	assert.True(t, HashSampler(0, nil).Sample(c))
+	c.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	c.Request.Header.Set("X-Request-ID", "ignored")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", DefaultSampleKey(c))
+	assert.False(t, HashSampler(0, nil).Sample(c))
+	assert.True(t, HashSampler(1, nil).Sample(c))
+}
+
+func TestLoggerSampler(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithSampler(SamplerFunc(func(*gin.Context) bool { return false }))))
+	r.GET("/ok", func(c *gin.Context) {})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusBadGateway)
+	})
+
+	performRequest(r, "GET", "/ok")
+	performRequest(r, "GET", "/fail")
+	assert.Equal(t, 1, strings.Count(buffer.String(), "\n"))
+	assert.Contains(t, buffer.String(), "/fail")
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+)
+
+// securityHeaderLength is the header value length above which a request is flagged.
+const securityHeaderLength = 8 << 10
+
+// securityFlags returns the names of the anomalies found in r that commonly
+// accompany request smuggling or probing. The checks are cheap heuristics, not
+// a substitute for a web application firewall.
+func securityFlags(r *http.Request) []string {
+	var flags []string
+	if len(r.TransferEncoding) > 0 && r.Header.Get("Content-Length") != "" {
+		flags = append(flags, "cl_te_conflict")
+	}
+	if len(r.Header.Values("Content-Length")) > 1 {
+		flags = append(flags, "multiple_content_length")
+	}
+	if hasLongHeader(r.Header) {
+		flags = append(flags, "long_header")
+	}
+	if strings.Contains(r.URL.Path, "\x00") || strings.Contains(strings.ToLower(r.URL.RawQuery), "%00") {
+		flags = append(flags, "null_byte")
+	}
+	return flags
+}
+
+// hasLongHeader reports whether a value of h is longer than securityHeaderLength.
+func hasLongHeader(h http.Header) bool {
+	for _, values := range h {
+		for _, v := range values {
+			if len(v) > securityHeaderLength {
+				return true
+			}
+		}
+	}
+	return false
+}
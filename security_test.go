@@ -0,0 +1,40 @@
+package logger
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityFlags(t *testing.T) {
+	req := httptest.NewRequest("POST", "/example", nil)
+	assert.Empty(t, securityFlags(req))
+
+	req.TransferEncoding = []string{"chunked"}
+	req.Header.Add("Content-Length", "4")
+	req.Header.Add("Content-Length", "8")
+	req.Header.Set("X-Padding", strings.Repeat("a", securityHeaderLength+1))
+	assert.Equal(t, []string{"cl_te_conflict", "multiple_content_length", "long_header"}, securityFlags(req))
+
+	req = httptest.NewRequest("GET", "/example?file=a%00.txt", nil)
+	assert.Equal(t, []string{"null_byte"}, securityFlags(req))
+}
+
+func TestLoggerSecurityFlags(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithSecurityFlags(true)))
+	r.GET("/example", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/example?file=a%00.txt")
+	assert.Contains(t, buffer.String(), `security_flags=["null_byte"]`)
+
+	buffer.Reset()
+	performRequest(r, "GET", "/example")
+	assert.NotContains(t, buffer.String(), "security_flags")
+}
@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SkipMatch selects what skip and only rules and path levels are matched against.
+type SkipMatch int
+
+const (
+	// SkipMatchDefault matches the exact paths of WithSkipPath and WithOnlyPaths
+	// against the URL path without the query string, so skipping "/health" also
+	// skips "/health?probe=1", and the other rules and path levels against the
+	// URL path followed by the query string, like SkipMatchURL.
+	SkipMatchDefault SkipMatch = iota
+	// SkipMatchPath matches every rule against the URL path without the query string.
+	SkipMatchPath
+	// SkipMatchURL matches every rule against the URL path followed by "?" and
+	// the raw query string when there is one.
+	SkipMatchURL
+)
+
+// exactTarget returns the string the exact paths are matched against for the request r.
+func (m SkipMatch) exactTarget(r *http.Request) string {
+	if m == SkipMatchURL {
+		return urlTarget(r)
+	}
+	return r.URL.Path
+}
+
+// target returns the string the other rules and path levels are matched
+// against for the request r.
+func (m SkipMatch) target(r *http.Request) string {
+	if m == SkipMatchPath {
+		return r.URL.Path
+	}
+	return urlTarget(r)
+}
+
+// urlTarget returns the URL path of r followed by "?" and the raw query string
+// when there is one.
+func urlTarget(r *http.Request) string {
+	if r.URL.RawQuery != "" {
+		return r.URL.Path + "?" + r.URL.RawQuery
+	}
+	return r.URL.Path
+}
+
+// matchPrefix reports whether s starts with one of prefixes.
+func matchPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRegexp reports whether s matches one of regs.
+func matchRegexp(s string, regs []*regexp.Regexp) bool {
+	for _, reg := range regs {
+		if reg.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob reports whether s matches one of the path.Match patterns.
+func matchGlob(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mustValidGlobs panics if one of the path.Match patterns is malformed.
+func mustValidGlobs(patterns []string) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("logger: invalid skip path glob %q: %v", pattern, err))
+		}
+	}
+}
+
+// Matcher decides which requests are logged from the skip and only rules of a
+// configuration: WithSkipPath, WithSkipPathRegexps, WithSkipPathPrefix,
+// WithSkipPathGlob, WithOnlyPaths, WithOnlyPathRegexps and WithSkipMatch. It is
+// immutable once built, so a single Matcher can be shared with WithMatcher by
+// many middleware instances, such as one SetLogger per route, instead of each
+// building its own.
+type Matcher struct {
+	mode        SkipMatch
+	skipPaths   map[string]struct{}
+	regexps     []*regexp.Regexp
+	prefixes    []string
+	globs       []string
+	onlyPaths   map[string]struct{}
+	onlyRegexps []*regexp.Regexp
+}
+
+// NewMatcher returns a Matcher built from the skip and only rules set by opts;
+// other options are ignored. It panics if a glob is malformed.
+func NewMatcher(opts ...Option) *Matcher {
+	cfg := &config{}
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+	return newMatcher(cfg)
+}
+
+// newMatcher returns a Matcher built from the rules of cfg.
+func newMatcher(cfg *config) *Matcher {
+	mustValidGlobs(cfg.skipPathGlobs)
+	m := &Matcher{
+		mode:        cfg.skipMatch,
+		regexps:     cfg.skipPathRegexps,
+		prefixes:    cfg.skipPathPrefixes,
+		globs:       cfg.skipPathGlobs,
+		onlyRegexps: cfg.onlyPathRegexps,
+	}
+	m.skipPaths = make(map[string]struct{}, len(cfg.skipPath))
+	for _, p := range cfg.skipPath {
+		m.skipPaths[p] = struct{}{}
+	}
+	if len(cfg.onlyPaths) > 0 {
+		m.onlyPaths = make(map[string]struct{}, len(cfg.onlyPaths))
+		for _, p := range cfg.onlyPaths {
+			m.onlyPaths[p] = struct{}{}
+		}
+	}
+	return m
+}
+
+// Skip reports whether the request r is excluded from logging.
+func (m *Matcher) Skip(r *http.Request) bool {
+	if _, ok := m.skipPaths[m.mode.exactTarget(r)]; ok {
+		return true
+	}
+	if len(m.regexps) == 0 && len(m.prefixes) == 0 && len(m.globs) == 0 &&
+		len(m.onlyPaths) == 0 && len(m.onlyRegexps) == 0 {
+		return false
+	}
+	target := m.mode.target(r)
+	if matchRegexp(target, m.regexps) || matchPrefix(target, m.prefixes) || matchGlob(target, m.globs) {
+		return true
+	}
+	if len(m.onlyPaths) > 0 || len(m.onlyRegexps) > 0 {
+		_, ok := m.onlyPaths[m.mode.exactTarget(r)]
+		return !ok && !matchRegexp(target, m.onlyRegexps)
+	}
+	return false
+}
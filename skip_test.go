@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerSkipMatch(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/health", SetLogger(
+		WithWriter(buffer),
+		WithSkipPath([]string{"/health"}),
+	), func(c *gin.Context) {})
+	r.GET("/probe", SetLogger(
+		WithWriter(buffer),
+		WithSkipMatch(SkipMatchURL),
+		WithSkipPathRegexps(regexp.MustCompile(`\?probe=1$`)),
+	), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/health?probe=1")
+	assert.Empty(t, buffer.String())
+
+	performRequest(r, "GET", "/probe?probe=1")
+	assert.Empty(t, buffer.String())
+
+	performRequest(r, "GET", "/probe")
+	assert.Contains(t, buffer.String(), "path=/probe")
+}
+
+func TestLoggerSkipMatchDefault(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithSkipPathRegexps(regexp.MustCompile(`\?debug=1$`)),
+		WithPathLevel(map[string]zerolog.Level{"/items?verbose=1": zerolog.WarnLevel}),
+	))
+	r.GET("/items", func(c *gin.Context) {})
+
+	// Regular expressions written against the query string still match.
+	performRequest(r, "GET", "/items?debug=1")
+	assert.Empty(t, buffer.String())
+
+	performRequest(r, "GET", "/items?verbose=1")
+	assert.Contains(t, buffer.String(), "WRN")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/items")
+	assert.Contains(t, buffer.String(), "INF")
+
+	m := NewMatcher(WithSkipMatch(SkipMatchPath), WithSkipPathRegexps(regexp.MustCompile(`^/items$`)))
+	assert.True(t, m.Skip(httptest.NewRequest("GET", "/items?debug=1", nil)))
+}
+
+func TestLoggerSkipPathPrefixAndGlob(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithSkipPathPrefix("/static/"),
+		WithSkipPathGlob("/assets/*/*.js"),
+	))
+	r.GET("/static/*file", func(c *gin.Context) {})
+	r.GET("/assets/*file", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/static/css/site.css")
+	performRequest(r, "GET", "/assets/v2/app.js")
+	assert.Empty(t, buffer.String())
+
+	performRequest(r, "GET", "/assets/v2/app.css")
+	assert.Contains(t, buffer.String(), "path=/assets/v2/app.css")
+
+	assert.Panics(t, func() {
+		SetLogger(WithSkipPathGlob("/assets/["))
+	})
+}
+
+func TestLoggerOnlyPaths(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithOnlyPaths("/login"),
+		WithOnlyPathRegexps(regexp.MustCompile(`^/api/`)),
+		WithSkipPath([]string{"/api/health"}),
+	))
+	r.GET("/login", func(c *gin.Context) {})
+	r.GET("/api/*rest", func(c *gin.Context) {})
+	r.GET("/home", func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/home")
+	performRequest(r, "GET", "/api/health")
+	assert.Empty(t, buffer.String())
+
+	performRequest(r, "GET", "/api/users")
+	assert.Contains(t, buffer.String(), "path=/api/users")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/login?next=/home")
+	assert.Contains(t, buffer.String(), "path=/login")
+}
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher(
+		WithSkipPath([]string{"/api/health"}),
+		WithOnlyPathRegexps(regexp.MustCompile(`^/api/`)),
+	)
+	assert.True(t, m.Skip(httptest.NewRequest("GET", "/api/health?probe=1", nil)))
+	assert.True(t, m.Skip(httptest.NewRequest("GET", "/home", nil)))
+	assert.False(t, m.Skip(httptest.NewRequest("GET", "/api/users", nil)))
+
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.GET("/api/health", SetLogger(WithWriter(buffer), WithMatcher(m)), func(c *gin.Context) {})
+	r.GET("/api/users", SetLogger(WithWriter(buffer), WithMatcher(m)), func(c *gin.Context) {})
+
+	performRequest(r, "GET", "/api/health")
+	assert.Empty(t, buffer.String())
+	performRequest(r, "GET", "/api/users")
+	assert.Contains(t, buffer.String(), "path=/api/users")
+}
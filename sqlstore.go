@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// sqlIdentifier matches the table names accepted by NewSQLStore.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// pruneEvery is the number of events SQLStore writes between two prunes.
+const pruneEvery = 1000
+
+// Retention limits the events kept by a SQLStore. Zero values keep everything.
+type Retention struct {
+	// MaxAge is the age above which events are deleted.
+	MaxAge time.Duration
+	// MaxRows is the number of most recent events kept.
+	MaxRows int
+}
+
+// SQLStore writes events into a table of a local database, such as SQLite, so
+// small self-hosted applications get a searchable access history without a log
+// stack. The database is opened by the caller with the driver of their choice,
+// e.g. modernc.org/sqlite or github.com/mattn/go-sqlite3, and must accept "?"
+// placeholders.
+//
+// The table holds one row per event with the columns time (Unix nanoseconds),
+// level, method, path, route, status, latency (nanoseconds), ip and event, the
+// JSON event as written. Events must be JSON objects, one per Write call, which
+// is what zerolog produces when it is not wrapped in a ConsoleWriter, e.g. with
+// WithAutoFormat. Each Write inserts a row: wrap the store in an AsyncWriter, or
+// use WithAsync, to keep the database off the request path.
+type SQLStore struct {
+	db        *sql.DB
+	table     string
+	retention Retention
+	now       func() time.Time
+
+	mu     sync.Mutex
+	writes int
+}
+
+// NewSQLStore returns a SQLStore writing to table in db, which it creates along
+// with an index on time when they do not exist. Every 1000 events, and on
+// Prune, it deletes the events outside of retention.
+func NewSQLStore(db *sql.DB, table string, retention Retention) (*SQLStore, error) {
+	if !sqlIdentifier.MatchString(table) {
+		return nil, fmt.Errorf("logger: invalid table name %q", table)
+	}
+	s := &SQLStore{db: db, table: table, retention: retention, now: time.Now}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS ` + table + ` (
+	id INTEGER PRIMARY KEY,
+	time INTEGER NOT NULL,
+	level TEXT NOT NULL,
+	method TEXT NOT NULL,
+	path TEXT NOT NULL,
+	route TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	latency INTEGER NOT NULL,
+	ip TEXT NOT NULL,
+	event TEXT NOT NULL
+)`,
+		`CREATE INDEX IF NOT EXISTS ` + table + `_time ON ` + table + ` (time)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Write inserts the event p.
+func (s *SQLStore) Write(p []byte) (int, error) {
+	params, err := decodeParams(p)
+	if err != nil {
+		return 0, fmt.Errorf("logger: sql store expects one JSON object per write: %w", err)
+	}
+	t := params.Time
+	if t.IsZero() {
+		t = s.now()
+	}
+	_, err = s.db.Exec(`INSERT INTO `+s.table+` (time, level, method, path, route, status, latency, ip, event) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.UnixNano(), params.Level.String(), params.Method, params.Path, params.Route,
+		params.Status, int64(params.Latency), params.ClientIP, strings.TrimRight(string(p), "\n"))
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.writes++
+	prune := s.writes%pruneEvery == 0
+	s.mu.Unlock()
+	if prune {
+		if err := s.Prune(context.Background()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Prune deletes the events outside of retention.
+func (s *SQLStore) Prune(ctx context.Context) error {
+	var errs []error
+	if s.retention.MaxAge > 0 {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM `+s.table+` WHERE time < ?`,
+			s.now().Add(-s.retention.MaxAge).UnixNano())
+		errs = append(errs, err)
+	}
+	if s.retention.MaxRows > 0 {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM `+s.table+` WHERE id NOT IN (SELECT id FROM `+s.table+` ORDER BY id DESC LIMIT ?)`,
+			s.retention.MaxRows)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// SQLQuery selects events of a SQLStore. Zero values match every event.
+type SQLQuery struct {
+	// Since and Until bound the time of the events, Until excluded.
+	Since, Until time.Time
+	// Method is the HTTP method of the requests.
+	Method string
+	// PathPrefix is a prefix of the logged path.
+	PathPrefix string
+	// Route is the route of the requests.
+	Route string
+	// MinStatus and MaxStatus bound the status of the responses, both included.
+	MinStatus, MaxStatus int
+	// Limit is the maximum number of events returned, the most recent first.
+	Limit int
+}
+
+// Query returns the events matching q, the most recent first.
+func (s *SQLStore) Query(ctx context.Context, q SQLQuery) ([]LogParams, error) {
+	var where []string
+	var args []any
+	cond := func(clause string, arg any) {
+		where = append(where, clause)
+		args = append(args, arg)
+	}
+	if !q.Since.IsZero() {
+		cond("time >= ?", q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		cond("time < ?", q.Until.UnixNano())
+	}
+	if q.Method != "" {
+		cond("method = ?", q.Method)
+	}
+	if q.PathPrefix != "" {
+		// SQL counts the length of strings in characters, not bytes. LIKE would
+		// need its wildcards escaped, and ignores case in SQLite and MySQL.
+		cond("substr(path, 1, ?) = ?", utf8.RuneCountInString(q.PathPrefix))
+		args = append(args, q.PathPrefix)
+	}
+	if q.Route != "" {
+		cond("route = ?", q.Route)
+	}
+	if q.MinStatus > 0 {
+		cond("status >= ?", q.MinStatus)
+	}
+	if q.MaxStatus > 0 {
+		cond("status <= ?", q.MaxStatus)
+	}
+
+	query := `SELECT event FROM ` + s.table
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	query += ` ORDER BY id DESC`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []LogParams
+	for rows.Next() {
+		var event string
+		if err := rows.Scan(&event); err != nil {
+			return nil, err
+		}
+		params, err := decodeParams([]byte(event))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, params)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,211 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeExec is a statement run on a fakeDB.
+type fakeExec struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeDB records the statements run through the "logger-fake" driver and
+// answers queries with rows.
+type fakeDB struct {
+	mu    sync.Mutex
+	execs []fakeExec
+	rows  [][]driver.Value
+}
+
+var (
+	fakeDBsMu sync.Mutex
+	fakeDBs   = map[string]*fakeDB{}
+)
+
+func init() {
+	sql.Register("logger-fake", fakeDriver{})
+}
+
+// openFakeDB returns a database backed by a new fakeDB.
+func openFakeDB(t *testing.T) (*sql.DB, *fakeDB) {
+	f := &fakeDB{}
+	fakeDBsMu.Lock()
+	fakeDBs[t.Name()] = f
+	fakeDBsMu.Unlock()
+	db, err := sql.Open("logger-fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, f
+}
+
+// statements returns the statements run on f starting with prefix.
+func (f *fakeDB) statements(prefix string) []fakeExec {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var execs []fakeExec
+	for _, e := range f.execs {
+		if strings.HasPrefix(e.query, prefix) {
+			execs = append(execs, e)
+		}
+	}
+	return execs
+}
+
+func (f *fakeDB) record(query string, args []driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, fakeExec{query: query, args: args})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDBsMu.Lock()
+	defer fakeDBsMu.Unlock()
+	return fakeConn{db: fakeDBs[name]}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{db: c.db, query: query}, nil
+}
+
+func (fakeConn) Close() error { return nil }
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return fakeTx(c), nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.record("COMMIT", nil)
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (fakeStmt) Close() error { return nil }
+
+func (fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.record(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.record(s.query, args)
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return &fakeRows{rows: s.db.rows}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (*fakeRows) Columns() []string { return []string{"event"} }
+
+func (*fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLStore(t *testing.T) {
+	db, f := openFakeDB(t)
+	_, err := NewSQLStore(db, "access; DROP TABLE users", Retention{})
+	assert.Error(t, err)
+
+	s, err := NewSQLStore(db, "access_log", Retention{MaxAge: time.Hour, MaxRows: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, f.statements("CREATE TABLE IF NOT EXISTS access_log"), 1)
+	assert.Len(t, f.statements("CREATE INDEX IF NOT EXISTS access_log_time"), 1)
+
+	event := `{"level":"warn","time":"2024-01-02T03:04:05Z","status":404,"method":"GET","path":"/missing","ip":"10.0.0.1","latency":1.5,"message":"Request"}` + "\n"
+	n, err := s.Write([]byte(event))
+	assert.NoError(t, err)
+	assert.Equal(t, len(event), n)
+	inserts := f.statements("INSERT INTO access_log")
+	if assert.Len(t, inserts, 1) {
+		assert.Equal(t, []driver.Value{
+			time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).UnixNano(), "warn", "GET", "/missing", "",
+			int64(404), int64(1500 * time.Microsecond), "10.0.0.1", strings.TrimSpace(event),
+		}, inserts[0].args)
+	}
+
+	_, err = s.Write([]byte("GET /missing 404\n"))
+	assert.Error(t, err)
+
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	assert.NoError(t, s.Prune(context.Background()))
+	deletes := f.statements("DELETE FROM access_log")
+	if assert.Len(t, deletes, 2) {
+		assert.Equal(t, []driver.Value{now.Add(-time.Hour).UnixNano()}, deletes[0].args)
+		assert.Equal(t, []driver.Value{int64(10)}, deletes[1].args)
+	}
+}
+
+func TestSQLStoreQuery(t *testing.T) {
+	db, f := openFakeDB(t)
+	s, err := NewSQLStore(db, "access_log", Retention{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.rows = [][]driver.Value{{`{"status":500,"method":"POST","path":"/api/orders"}`}}
+
+	events, err := s.Query(context.Background(), SQLQuery{PathPrefix: "/api", MinStatus: 500, Limit: 5})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, 500, events[0].Status)
+		assert.Equal(t, "/api/orders", events[0].Path)
+	}
+	queries := f.statements("SELECT event FROM access_log")
+	if assert.Len(t, queries, 1) {
+		assert.Equal(t, "SELECT event FROM access_log WHERE substr(path, 1, ?) = ? AND status >= ? ORDER BY id DESC LIMIT ?", queries[0].query)
+		assert.Equal(t, []driver.Value{int64(4), "/api", int64(500), int64(5)}, queries[0].args)
+	}
+
+	// The length of the prefix is counted in characters, as SQL does.
+	_, err = s.Query(context.Background(), SQLQuery{PathPrefix: "/café"})
+	assert.NoError(t, err)
+	queries = f.statements("SELECT event FROM access_log")
+	if assert.Len(t, queries, 2) {
+		assert.Equal(t, []driver.Value{int64(5), "/café"}, queries[1].args)
+	}
+}
@@ -0,0 +1,195 @@
+package logger
+
+import (
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute is the route requests not matching any route are counted under,
+// so unknown paths cannot grow the counters without bound.
+const unmatchedRoute = "<unmatched>"
+
+// otherMethod is the method unmatched requests with a non-standard method are
+// counted under, so made-up methods cannot grow the counters either.
+const otherMethod = "OTHER"
+
+// standardMethods are the methods unmatched requests are counted by.
+var standardMethods = map[string]struct{}{
+	http.MethodGet: {}, http.MethodHead: {}, http.MethodPost: {}, http.MethodPut: {}, http.MethodPatch: {},
+	http.MethodDelete: {}, http.MethodConnect: {}, http.MethodOptions: {}, http.MethodTrace: {},
+}
+
+// RouteStats holds the counters of a route collected with WithRouteStats.
+type RouteStats struct {
+	Method string `json:"method"`
+	Route  string `json:"route"`
+	// Requests is the number of requests served by the route.
+	Requests uint64 `json:"requests"`
+	// Errors is the number of requests answered with a 5xx status.
+	Errors uint64 `json:"errors"`
+	// TotalLatency is the cumulative latency of the requests.
+	TotalLatency time.Duration `json:"total_latency"`
+	// InFlight is the number of requests being served by the route.
+	InFlight int64 `json:"in_flight"`
+}
+
+// AvgLatency returns the average latency of the requests of the route.
+func (s RouteStats) AvgLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Requests)
+}
+
+type routeKey struct {
+	method, route string
+}
+
+// routeShard is a cell of a routeCounter holding its counters in a single
+// cache line. A request may start and end on different shards, so inFlight is
+// only meaningful summed.
+type routeShard struct {
+	requests, errors, latency, inFlight atomic.Int64
+	_                                   [32]byte
+}
+
+// routeCounter holds the counters of a route. They are sharded like a
+// shardedCounter, so requests served concurrently by the same route do not
+// contend, with a shard per cache line for all the counters to keep the
+// memory of a route to counterShards cache lines.
+type routeCounter struct {
+	shards []routeShard
+}
+
+// shard returns a shard picked at random.
+func (rc *routeCounter) shard() *routeShard {
+	return &rc.shards[rand.Uint32()&uint32(len(rc.shards)-1)]
+}
+
+// start counts a request being served until done is called.
+func (rc *routeCounter) start() {
+	rc.shard().inFlight.Add(1)
+}
+
+// done counts the end of a request counted by start.
+func (rc *routeCounter) done() {
+	rc.shard().inFlight.Add(-1)
+}
+
+// add counts a request served with latency, failed when failed is set.
+func (rc *routeCounter) add(latency time.Duration, failed bool) {
+	shard := rc.shard()
+	shard.requests.Add(1)
+	if failed {
+		shard.errors.Add(1)
+	}
+	shard.latency.Add(int64(latency))
+}
+
+// load returns the sums of the counters.
+func (rc *routeCounter) load() (requests, errors int64, latency time.Duration, inFlight int64) {
+	for i := range rc.shards {
+		requests += rc.shards[i].requests.Load()
+		errors += rc.shards[i].errors.Load()
+		latency += time.Duration(rc.shards[i].latency.Load())
+		inFlight += rc.shards[i].inFlight.Load()
+	}
+	return requests, errors, latency, inFlight
+}
+
+// routeCounters holds the counters reported by Stats. The routes are looked up
+// without locking once created.
+type routeCounters struct {
+	routes sync.Map // routeKey -> *routeCounter
+}
+
+func newRouteCounters() *routeCounters {
+	return &routeCounters{}
+}
+
+var routeStats = newRouteCounters()
+
+// counter returns the counters of the route of the request.
+func (r *routeCounters) counter(c *gin.Context) *routeCounter {
+	key := routeKey{method: c.Request.Method, route: c.FullPath()}
+	if key.route == "" {
+		// Gin runs middleware for unmatched requests whatever their method.
+		key.route = unmatchedRoute
+		if _, ok := standardMethods[key.method]; !ok {
+			key.method = otherMethod
+		}
+	}
+
+	v, ok := r.routes.Load(key)
+	if !ok {
+		v, _ = r.routes.LoadOrStore(key, &routeCounter{shards: make([]routeShard, counterShards)})
+	}
+	return v.(*routeCounter)
+}
+
+// Stats returns the per-route counters collected by the SetLogger instances
+// using WithRouteStats, busiest routes first.
+func Stats() []RouteStats {
+	stats := make([]RouteStats, 0)
+	routeStats.routes.Range(func(k, v any) bool {
+		key := k.(routeKey)
+		requests, errors, latency, inFlight := v.(*routeCounter).load()
+		stats = append(stats, RouteStats{
+			Method:       key.method,
+			Route:        key.route,
+			Requests:     uint64(requests),
+			Errors:       uint64(errors),
+			TotalLatency: latency,
+			InFlight:     inFlight,
+		})
+		return true
+	})
+
+	sortStats(stats, "requests")
+	return stats
+}
+
+// sortStats sorts stats in descending order of requests, errors or average latency.
+func sortStats(stats []RouteStats, by string) {
+	key := func(s RouteStats) int64 { return int64(s.Requests) }
+	switch by {
+	case "errors":
+		key = func(s RouteStats) int64 { return int64(s.Errors) }
+	case "latency":
+		key = func(s RouteStats) int64 { return int64(s.AvgLatency()) }
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if ki, kj := key(stats[i]), key(stats[j]); ki != kj {
+			return ki > kj
+		}
+		if stats[i].Route != stats[j].Route {
+			return stats[i].Route < stats[j].Route
+		}
+		return stats[i].Method < stats[j].Method
+	})
+}
+
+// StatsHandler returns a handler rendering the n busiest routes as JSON. The
+// "sort" query parameter ranks them by "requests" (default), "latency" or
+// "errors" instead, and "n" overrides the number of routes.
+func StatsHandler(n int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := n
+		if v, err := strconv.Atoi(c.Query("n")); err == nil && v > 0 {
+			limit = v
+		}
+		stats := Stats()
+		sortStats(stats, c.Query("sort"))
+		if limit > 0 && len(stats) > limit {
+			stats = stats[:limit]
+		}
+		c.JSON(http.StatusOK, stats)
+	}
+}
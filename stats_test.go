@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteStats(t *testing.T) {
+	routeStats = newRouteCounters()
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(new(bytes.Buffer)), WithRouteStats(true), WithSkipPath([]string{"/busy/1"})))
+	r.GET("/busy/:id", func(c *gin.Context) {})
+	r.GET("/slow", func(c *gin.Context) {
+		time.Sleep(5 * time.Millisecond)
+		c.Status(http.StatusInternalServerError)
+	})
+	r.GET("/stats", StatsHandler(1))
+
+	for i := 0; i < 3; i++ {
+		performRequest(r, "GET", "/busy/1")
+	}
+	performRequest(r, "GET", "/slow")
+	performRequest(r, "GET", "/missing")
+	performRequest(r, "GET", "/missing/too")
+	performRequest(r, "BREW", "/missing")
+	performRequest(r, "WHEN", "/missing")
+
+	// Made-up methods of unmatched requests share a single key.
+	var stats []RouteStats
+	others := 0
+	for _, s := range Stats() {
+		if s.Method == otherMethod {
+			others++
+			assert.Equal(t, unmatchedRoute, s.Route)
+			assert.Equal(t, uint64(2), s.Requests)
+			continue
+		}
+		stats = append(stats, s)
+	}
+	assert.Equal(t, 1, others)
+	assert.Len(t, stats, 3)
+	assert.Equal(t, "/busy/:id", stats[0].Route)
+	assert.Equal(t, uint64(3), stats[0].Requests)
+	assert.Equal(t, unmatchedRoute, stats[1].Route)
+	assert.Equal(t, uint64(2), stats[1].Requests)
+	assert.Equal(t, "/slow", stats[2].Route)
+	assert.Equal(t, uint64(1), stats[2].Errors)
+	assert.GreaterOrEqual(t, stats[2].AvgLatency(), 5*time.Millisecond)
+
+	var top []RouteStats
+	w := performRequest(r, "GET", "/stats?sort=latency")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &top))
+	assert.Len(t, top, 1)
+	assert.Equal(t, "/slow", top[0].Route)
+
+	w = performRequest(r, "GET", "/stats?sort=errors&n=2")
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &top))
+	assert.Len(t, top, 2)
+	assert.Equal(t, "/slow", top[0].Route)
+}
+
+func TestRouteCounter(t *testing.T) {
+	rc := &routeCounter{shards: make([]routeShard, counterShards)}
+	for i := 0; i < 100; i++ {
+		rc.add(time.Millisecond, i%10 == 0)
+	}
+	for i := 0; i < 100; i++ {
+		rc.start()
+	}
+	for i := 0; i < 98; i++ {
+		rc.done()
+	}
+	requests, errors, latency, inFlight := rc.load()
+	assert.Equal(t, int64(100), requests)
+	assert.Equal(t, int64(10), errors)
+	assert.Equal(t, 100*time.Millisecond, latency)
+	assert.Equal(t, int64(2), inFlight)
+	// The counters of a shard share one cache line.
+	assert.Equal(t, uintptr(64), unsafe.Sizeof(routeShard{}))
+}
+
+func TestRouteStatsInFlight(t *testing.T) {
+	routeStats = newRouteCounters()
+
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(new(bytes.Buffer)), WithRouteStats(true)))
+	var during int64
+	r.GET("/work", func(c *gin.Context) {
+		for _, s := range Stats() {
+			if s.Route == "/work" {
+				during = s.InFlight
+			}
+		}
+	})
+
+	performRequest(r, "GET", "/work")
+	performRequest(r, "GET", "/work")
+	assert.Equal(t, int64(1), during)
+	stats := Stats()
+	if assert.Len(t, stats, 1) {
+		assert.Equal(t, int64(0), stats[0].InFlight)
+		assert.Equal(t, uint64(2), stats[0].Requests)
+	}
+}
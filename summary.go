@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// SLO is a per-route service level objective used by WithSLO.
+type SLO struct {
+	// Latency is the target latency. Slower requests breach the objective.
+	// Zero disables the latency target.
+	Latency time.Duration
+	// MaxStatus is the highest status code that still meets the objective.
+	// Zero means any status below 500.
+	MaxStatus int
+	// Objective is the fraction of requests expected to meet the targets,
+	// e.g. 0.99. It sizes the error budget reported in the summary and
+	// defaults to 0.99.
+	Objective float64
+}
+
+func (s SLO) breached(status int, latency time.Duration) bool {
+	maxStatus := s.MaxStatus
+	if maxStatus == 0 {
+		maxStatus = http.StatusInternalServerError - 1
+	}
+	return status > maxStatus || (s.Latency > 0 && latency > s.Latency)
+}
+
+func (s SLO) objective() float64 {
+	if s.Objective <= 0 || s.Objective >= 1 {
+		return 0.99
+	}
+	return s.Objective
+}
+
+// sloCounter counts requests and breaches of a route since the last summary.
+type sloCounter struct {
+	requests int
+	breaches int
+}
+
+// Apdex classes of a request, see WithApdex.
+const (
+	apdexSatisfied  = "satisfied"
+	apdexTolerating = "tolerating"
+	apdexFrustrated = "frustrated"
+)
+
+// apdexClass classifies a request against the Apdex threshold: satisfied up to the
+// threshold, tolerating up to four times the threshold and frustrated beyond it or
+// when the request failed with a 5xx status.
+func apdexClass(threshold time.Duration, status int, latency time.Duration) string {
+	switch {
+	case status >= http.StatusInternalServerError || latency > 4*threshold:
+		return apdexFrustrated
+	case latency > threshold:
+		return apdexTolerating
+	default:
+		return apdexSatisfied
+	}
+}
+
+// apdexCounter counts requests per Apdex class since the last summary.
+type apdexCounter struct {
+	satisfied  int
+	tolerating int
+	frustrated int
+}
+
+// score returns the Apdex score, (satisfied + tolerating/2) / total.
+func (a apdexCounter) score() (float64, bool) {
+	total := a.satisfied + a.tolerating + a.frustrated
+	if total == 0 {
+		return 0, false
+	}
+	return (float64(a.satisfied) + float64(a.tolerating)/2) / float64(total), true
+}
+
+// summary aggregates per-route measurements and periodically writes them as a
+// single "Summary" event. It is driven by request completion instead of a
+// background goroutine, so a summary is written by the first request completing
+// after the interval elapsed.
+type summary struct {
+	interval time.Duration
+
+	mu    sync.Mutex
+	last  time.Time
+	slo   map[string]*sloCounter
+	apdex apdexCounter
+}
+
+func newSummary(interval time.Duration, now time.Time) *summary {
+	return &summary{
+		interval: interval,
+		last:     now,
+		slo:      make(map[string]*sloCounter),
+	}
+}
+
+// recordSLO counts a request to route and whether it breached its SLO.
+func (s *summary) recordSLO(route string, breached bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.slo[route]
+	if !ok {
+		sc = &sloCounter{}
+		s.slo[route] = sc
+	}
+	sc.requests++
+	if breached {
+		sc.breaches++
+	}
+}
+
+// recordApdex counts a request in its Apdex class.
+func (s *summary) recordApdex(class string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch class {
+	case apdexSatisfied:
+		s.apdex.satisfied++
+	case apdexTolerating:
+		s.apdex.tolerating++
+	default:
+		s.apdex.frustrated++
+	}
+}
+
+// flush writes the summary to l and resets the counters if the interval elapsed.
+func (s *summary) flush(l zerolog.Logger, slos map[string]SLO, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.last) < s.interval {
+		s.mu.Unlock()
+		return
+	}
+	counters := s.slo
+	s.slo = make(map[string]*sloCounter, len(counters))
+	apdex := s.apdex
+	s.apdex = apdexCounter{}
+	since := s.last
+	s.last = now
+	s.mu.Unlock()
+
+	evt := l.Info().Dur("interval", now.Sub(since))
+	if score, ok := apdex.score(); ok {
+		evt = evt.Float64("apdex", score)
+	}
+	if len(counters) > 0 {
+		routes := zerolog.Dict()
+		for route, sc := range counters {
+			// Burn rate is the observed breach ratio relative to the error
+			// budget: 1 consumes the budget exactly over the SLO window.
+			budget := 1 - slos[route].objective()
+			routes = routes.Dict(route, zerolog.Dict().
+				Int("requests", sc.requests).
+				Int("breaches", sc.breaches).
+				Float64("burn_rate", float64(sc.breaches)/float64(sc.requests)/budget))
+		}
+		evt = evt.Dict("slo", routes)
+	}
+	evt.Msg("Summary")
+}
+
+// routeOf returns the route template of the request, or its URL path for
+// requests that did not match a route.
+func routeOf(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return c.Request.URL.Path
+}
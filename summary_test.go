@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLOBreached(t *testing.T) {
+	slo := SLO{Latency: 100 * time.Millisecond}
+	assert.False(t, slo.breached(http.StatusOK, 50*time.Millisecond))
+	assert.False(t, slo.breached(http.StatusNotFound, 50*time.Millisecond))
+	assert.True(t, slo.breached(http.StatusOK, 150*time.Millisecond))
+	assert.True(t, slo.breached(http.StatusBadGateway, 50*time.Millisecond))
+
+	strict := SLO{MaxStatus: 399}
+	assert.True(t, strict.breached(http.StatusNotFound, time.Hour+1))
+	assert.Equal(t, 0.99, strict.objective())
+}
+
+func TestLoggerSLO(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithDefaultLevel(zerolog.DebugLevel),
+		WithSLO(map[string]SLO{"/users/:id": {Latency: time.Hour, Objective: 0.9}}),
+	))
+	r.GET("/users/:id", func(c *gin.Context) {
+		if c.Param("id") == "0" {
+			c.Status(http.StatusServiceUnavailable)
+		}
+	})
+	r.GET("/other", func(c *gin.Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	performRequest(r, "GET", "/users/1")
+	assert.Contains(t, buffer.String(), "DBG")
+	assert.NotContains(t, buffer.String(), "slo_breach")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/users/0")
+	assert.Contains(t, buffer.String(), "slo_breach=true")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/other")
+	assert.NotContains(t, buffer.String(), "slo_breach")
+}
+
+func TestLoggerSLOEscalatesLevel(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(buffer),
+		WithDefaultLevel(zerolog.DebugLevel),
+		WithSLO(map[string]SLO{"/slow": {Latency: time.Nanosecond}}),
+	))
+	r.GET("/slow", func(c *gin.Context) {
+		time.Sleep(time.Millisecond)
+	})
+
+	performRequest(r, "GET", "/slow")
+	assert.Contains(t, buffer.String(), "WRN")
+	assert.Contains(t, buffer.String(), "slo_breach=true")
+}
+
+func TestSummaryFlush(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	l := zerolog.New(buffer)
+	start := time.Now()
+	s := newSummary(time.Minute, start)
+	slos := map[string]SLO{"/a": {Objective: 0.5}}
+	for i := 0; i < 10; i++ {
+		s.recordSLO("/a", i < 2)
+	}
+
+	s.flush(l, slos, start.Add(time.Second))
+	assert.Empty(t, buffer.String())
+
+	s.flush(l, slos, start.Add(time.Minute))
+	assert.Contains(t, buffer.String(), `"message":"Summary"`)
+	assert.Contains(t, buffer.String(), `"slo":{"/a":{"requests":10,"breaches":2,"burn_rate":0.4}}`)
+
+	buffer.Reset()
+	s.flush(l, slos, start.Add(2*time.Minute))
+	assert.True(t, strings.Contains(buffer.String(), `"message":"Summary"`))
+	assert.NotContains(t, buffer.String(), `"slo"`)
+}
+
+func TestApdex(t *testing.T) {
+	threshold := 100 * time.Millisecond
+	assert.Equal(t, apdexSatisfied, apdexClass(threshold, http.StatusOK, threshold))
+	assert.Equal(t, apdexTolerating, apdexClass(threshold, http.StatusNotFound, 4*threshold))
+	assert.Equal(t, apdexFrustrated, apdexClass(threshold, http.StatusOK, 4*threshold+1))
+	assert.Equal(t, apdexFrustrated, apdexClass(threshold, http.StatusBadGateway, 0))
+
+	buffer := new(bytes.Buffer)
+	l := zerolog.New(buffer)
+	start := time.Now()
+	s := newSummary(time.Minute, start)
+	for _, class := range []string{apdexSatisfied, apdexSatisfied, apdexTolerating, apdexFrustrated} {
+		s.recordApdex(class)
+	}
+	s.flush(l, nil, start.Add(time.Minute))
+	assert.Contains(t, buffer.String(), `"apdex":0.625`)
+
+	buffer.Reset()
+	s.flush(l, nil, start.Add(2*time.Minute))
+	assert.NotContains(t, buffer.String(), "apdex")
+}
+
+func TestLoggerApdex(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithApdex(time.Hour)))
+	r.GET("/example", func(c *gin.Context) {})
+	r.GET("/fail", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	performRequest(r, "GET", "/example")
+	assert.Contains(t, buffer.String(), "apdex=satisfied")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/fail")
+	assert.Contains(t, buffer.String(), "apdex=frustrated")
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"net/http"
+	"os"
+	"runtime"
+
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSynchronousOneWritePerEvent(t *testing.T) {
+	for name, opts := range map[string][]Option{
+		"console": nil,
+		"json":    {WithAutoFormat()},
+		"dev":     {WithDevFormat()},
+		"gin":     {WithGinFormat()},
+		"enriched": {
+			WithCorrelationID(),
+			WithRouteParams(true),
+			WithTimingSplit(),
+			WithCrashDump(t.TempDir()+"/crash.log", 16),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := &countingWriter{}
+			gin.SetMode(gin.ReleaseMode)
+			r := gin.New()
+			r.Use(SetLogger(append([]Option{WithWriter(out), WithSynchronous()}, opts...)...))
+			r.GET("/users/:id", func(c *gin.Context) {
+				l := Get(c)
+				l.Info().Msg("handler")
+				c.String(http.StatusOK, "ok")
+			})
+
+			for i := 0; i < 5; i++ {
+				performRequest(r, "GET", "/users/42")
+			}
+			want := 10
+			if name == "gin" {
+				// The gin format drops handler events.
+				want = 5
+			}
+			assert.Equal(t, want, out.count())
+		})
+	}
+}
+
+func TestSynchronousNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	out := &countingWriter{}
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(
+		WithWriter(out),
+		WithSynchronous(),
+		WithAsync(16),
+		WithBatching(10, 1<<10, time.Millisecond),
+		WithResolveClientHost(true, time.Minute),
+		WithCrashDump(t.TempDir()+"/crash.log", 16, os.Interrupt),
+	))
+	r.GET("/example", func(c *gin.Context) {})
+
+	for i := 0; i < 20; i++ {
+		performRequest(r, "GET", "/example")
+	}
+	time.Sleep(5 * time.Millisecond)
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	assert.Equal(t, 20, out.count())
+
+	_, err := New(WithSynchronous(), WithAsync(16))
+	assert.ErrorIs(t, err, ErrOptionConflict)
+}
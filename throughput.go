@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// meteredReader counts the request body bytes the handler reads and when it read
+// the last of them.
+type meteredReader struct {
+	io.ReadCloser
+	now  func() time.Time
+	n    int64
+	last time.Time
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.n += int64(n)
+		r.last = r.now()
+	}
+	return n, err
+}
+
+// rate returns n bytes over d in bytes per second.
+func rate(n int64, d time.Duration) (int64, bool) {
+	if n <= 0 || d <= 0 {
+		return 0, false
+	}
+	return int64(float64(n) / d.Seconds()), true
+}
+
+// throughputFields adds the effective request read and response write rates, in
+// bytes per second, for transfers of at least minBytes. The request rate runs from
+// the start of the request to the last body byte read; the response rate from the
+// first byte written to the completion of the request.
+func throughputFields(c *gin.Context, evt *zerolog.Event, r *meteredReader, w *responseWriter, minBytes int64, start, end time.Time) *zerolog.Event {
+	if r != nil && r.n >= minBytes {
+		if bps, ok := rate(r.n, r.last.Sub(start)); ok {
+			evt = evt.Int64("request_throughput", bps)
+		}
+	}
+	if size := int64(c.Writer.Size()); w != nil && size >= minBytes && !w.firstWrite.IsZero() {
+		if bps, ok := rate(size, end.Sub(w.firstWrite)); ok {
+			evt = evt.Int64("response_throughput", bps)
+		}
+	}
+	return evt
+}
@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerThroughput(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), step: 500 * time.Millisecond}
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.POST("/echo", SetLogger(WithWriter(buffer), WithClock(clock), WithThroughput(0)), func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "text/plain", b)
+	})
+	r.POST("/small", SetLogger(WithWriter(buffer), WithClock(clock), WithThroughput(1000)), func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "text/plain", b)
+	})
+
+	body := strings.Repeat("x", 100)
+	performBodyRequest(r, "POST", "/echo", "text/plain", body)
+	assert.Contains(t, buffer.String(), "request_throughput=200")
+	assert.Contains(t, buffer.String(), "response_throughput=200")
+
+	buffer.Reset()
+	performBodyRequest(r, "POST", "/small", "text/plain", body)
+	assert.NotContains(t, buffer.String(), "throughput")
+}
+
+func TestRate(t *testing.T) {
+	bps, ok := rate(1<<20, 2*time.Second)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1<<19), bps)
+
+	_, ok = rate(10, 0)
+	assert.False(t, ok)
+	_, ok = rate(0, time.Second)
+	assert.False(t, ok)
+}
+
+func TestLoggerTimingSplit(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), step: 100 * time.Millisecond}
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithClock(clock), WithTimingSplit()))
+	r.GET("/stream", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("a")
+		c.Writer.Flush()
+	})
+
+	performRequest(r, "GET", "/stream")
+	// start, first write, write begin/end, flush begin/end, end: 600ms of which 200ms writing.
+	assert.Contains(t, buffer.String(), "handler_time=400")
+	assert.Contains(t, buffer.String(), "write_time=200")
+	assert.Contains(t, buffer.String(), "latency=600")
+}
+
+func TestLoggerStreamingFields(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithStreamingFields(true)))
+	r.GET("/events", func(c *gin.Context) {
+		for i := 0; i < 3; i++ {
+			c.SSEvent("tick", i)
+			c.Writer.Flush()
+		}
+	})
+	r.GET("/plain", func(c *gin.Context) {
+		c.String(http.StatusOK, "done")
+	})
+
+	performRequest(r, "GET", "/events")
+	assert.Contains(t, buffer.String(), "chunks=")
+	assert.Contains(t, buffer.String(), "flushes=3")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/plain")
+	assert.NotContains(t, buffer.String(), "chunks")
+}
+
+func TestLoggerEarlyHints(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	clock := &stepClock{now: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), step: 100 * time.Millisecond}
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithClock(clock), WithEarlyHints(true)))
+	r.GET("/hints", func(c *gin.Context) {
+		c.Header("Link", "</style.css>; rel=preload; as=style")
+		c.Status(http.StatusEarlyHints)
+		c.String(http.StatusOK, "page")
+	})
+	r.GET("/plain", func(c *gin.Context) {
+		c.String(http.StatusOK, "page")
+	})
+
+	performRequest(r, "GET", "/hints")
+	assert.Contains(t, buffer.String(), "informational_status=103")
+	assert.Contains(t, buffer.String(), "status=200")
+	assert.Contains(t, buffer.String(), "hint_latency=100")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/plain")
+	assert.NotContains(t, buffer.String(), "informational_status")
+}
+
+func TestLoggerSuperfluousWrites(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithSuperfluousWrites(true)))
+	r.GET("/double", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+		c.String(http.StatusInternalServerError, "failed")
+	})
+	r.GET("/single", func(c *gin.Context) {
+		c.Status(http.StatusAccepted)
+		c.String(http.StatusOK, "ok")
+	})
+
+	performRequest(r, "GET", "/double")
+	assert.Contains(t, buffer.String(), "status=200")
+	assert.Contains(t, buffer.String(), "superfluous_write=500")
+
+	buffer.Reset()
+	performRequest(r, "GET", "/single")
+	assert.NotContains(t, buffer.String(), "superfluous_write")
+}
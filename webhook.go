@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// Webhook describes how a webhook-receiving route is signed, see WithWebhooks.
+type Webhook struct {
+	// SignatureHeaders lists the headers that may carry the signature, in order
+	// of preference, e.g. X-Hub-Signature-256 then X-Hub-Signature.
+	SignatureHeaders []string
+	// EventHeader is the header naming the event type, e.g. X-GitHub-Event.
+	EventHeader string
+	// Verify, when set, reports whether the signature is valid for the request
+	// body. It runs after the handler on a copy of bodies up to 1 MiB.
+	Verify func(signature string, body []byte) bool
+}
+
+// GitHubWebhook returns the Webhook of GitHub deliveries signed with secret.
+func GitHubWebhook(secret []byte) Webhook {
+	return Webhook{
+		SignatureHeaders: []string{"X-Hub-Signature-256"},
+		EventHeader:      "X-GitHub-Event",
+		Verify:           HMACSHA256Verifier(secret, "sha256="),
+	}
+}
+
+// HMACSHA256Verifier returns a Webhook verifier accepting signatures made of
+// prefix followed by the hex encoded HMAC-SHA256 of the body keyed with secret.
+func HMACSHA256Verifier(secret []byte, prefix string) func(signature string, body []byte) bool {
+	return func(signature string, body []byte) bool {
+		sig, ok := strings.CutPrefix(signature, prefix)
+		if !ok {
+			return false
+		}
+		got, err := hex.DecodeString(sig)
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+}
+
+// webhookFields adds a "webhook" group with the signature header found, whether
+// the verifier accepted the signature and the event type.
+func webhookFields(c *gin.Context, evt *zerolog.Event, wh Webhook, body []byte, hasBody bool) *zerolog.Event {
+	dict := zerolog.Dict()
+	var name, signature string
+	for _, h := range wh.SignatureHeaders {
+		if v := c.GetHeader(h); v != "" {
+			name, signature = h, v
+			break
+		}
+	}
+	if name != "" {
+		dict = dict.Str("signature_header", name)
+	}
+	if wh.Verify != nil && hasBody {
+		dict = dict.Bool("verified", signature != "" && wh.Verify(signature, body))
+	}
+	if wh.EventHeader != "" {
+		if event := c.GetHeader(wh.EventHeader); event != "" {
+			dict = dict.Str("event", event)
+		}
+	}
+	return evt.Dict("webhook", dict)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerWebhooks(t *testing.T) {
+	secret := []byte("s3cr3t")
+	payload := `{"action":"opened"}`
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	buffer := new(bytes.Buffer)
+	var received string
+	gin.SetMode(gin.ReleaseMode)
+	r := gin.New()
+	r.Use(SetLogger(WithWriter(buffer), WithWebhooks(map[string]Webhook{
+		"/hooks/github": GitHubWebhook(secret),
+	})))
+	r.POST("/hooks/github", func(c *gin.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		received = string(b)
+	})
+	r.POST("/other", func(c *gin.Context) {})
+
+	deliver := func(path, signature string) {
+		req := httptest.NewRequest("POST", path, strings.NewReader(payload))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+		if signature != "" {
+			req.Header.Set("X-Hub-Signature-256", signature)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	deliver("/hooks/github", valid)
+	assert.Equal(t, payload, received)
+	assert.Contains(t, buffer.String(), `webhook={"event":"pull_request","signature_header":"X-Hub-Signature-256","verified":true}`)
+
+	buffer.Reset()
+	deliver("/hooks/github", "sha256=00")
+	assert.Contains(t, buffer.String(), `"verified":false`)
+
+	buffer.Reset()
+	deliver("/hooks/github", "")
+	assert.Contains(t, buffer.String(), `webhook={"event":"pull_request","verified":false}`)
+
+	buffer.Reset()
+	deliver("/other", valid)
+	assert.NotContains(t, buffer.String(), "webhook")
+}
+
+func TestHMACSHA256Verifier(t *testing.T) {
+	verify := HMACSHA256Verifier([]byte("key"), "sha256=")
+	assert.False(t, verify("sha1=abc", nil))
+	assert.False(t, verify("sha256=zz", nil))
+}